@@ -0,0 +1,12 @@
+module github.com/gear5sh/gear5/adapters/parquet
+
+go 1.22
+
+toolchain go1.22.3
+
+require (
+	github.com/apache/arrow/go/v16 v16.0.0
+	github.com/gear5sh/gear5 v0.0.0-20230727050722-6795340c7033
+	github.com/goccy/go-json v0.10.3
+	github.com/stretchr/testify v1.9.0
+)
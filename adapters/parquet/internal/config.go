@@ -0,0 +1,78 @@
+package adapter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apache/arrow/go/v16/parquet/compress"
+)
+
+var codecs = map[string]compress.Compression{
+	"uncompressed": compress.Codecs.Uncompressed,
+	"snappy":       compress.Codecs.Snappy,
+	"gzip":         compress.Codecs.Gzip,
+	"brotli":       compress.Codecs.Brotli,
+	"lz4":          compress.Codecs.Lz4,
+	"zstd":         compress.Codecs.Zstd,
+}
+
+type Config struct {
+	// Local directory where parquet files are written; every stream gets its own sub directory
+	//
+	// @jsonschema(
+	// required=true
+	// )
+	Path string `json:"local_path"`
+	// Number of rows buffered in memory before a row group is written
+	//
+	// @jsonschema(
+	// default=100000
+	// )
+	RowGroupSize int `json:"row_group_size"`
+	// Maximum number of rows in a single file before rolling to a new file
+	//
+	// @jsonschema(
+	// default=1000000
+	// )
+	MaxRowsPerFile int `json:"max_rows_per_file"`
+	// Compression codec used for column chunks
+	//
+	// @jsonschema(
+	// enum=["uncompressed","snappy","gzip","brotli","lz4","zstd"],
+	// default="snappy"
+	// )
+	Compression string `json:"compression"`
+}
+
+func (c *Config) Validate() error {
+	if c.Path == "" {
+		return fmt.Errorf("'local_path' is required parameter")
+	}
+
+	if c.RowGroupSize < 0 || c.MaxRowsPerFile < 0 {
+		return fmt.Errorf("'row_group_size' and 'max_rows_per_file' can not be negative")
+	}
+
+	if c.RowGroupSize == 0 {
+		c.RowGroupSize = 100000
+	}
+
+	if c.MaxRowsPerFile == 0 {
+		c.MaxRowsPerFile = 1000000
+	}
+
+	if c.Compression == "" {
+		c.Compression = "snappy"
+	}
+
+	c.Compression = strings.ToLower(c.Compression)
+	if _, found := codecs[c.Compression]; !found {
+		return fmt.Errorf("unsupported compression [%s]", c.Compression)
+	}
+
+	return nil
+}
+
+func (c *Config) Codec() compress.Compression {
+	return codecs[c.Compression]
+}
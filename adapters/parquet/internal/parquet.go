@@ -0,0 +1,121 @@
+package adapter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gear5sh/gear5/protocol"
+	"github.com/gear5sh/gear5/types"
+	"github.com/gear5sh/gear5/utils"
+)
+
+type Parquet struct {
+	config  *Config
+	streams map[string]*writer // stream writers by stream identifier
+}
+
+func (p *Parquet) Config() any {
+	p.config = &Config{}
+
+	return p.config
+}
+
+func (p *Parquet) Spec() any {
+	return Config{}
+}
+
+func (p *Parquet) Type() string {
+	return "Parquet"
+}
+
+func (p *Parquet) Check() error {
+	err := p.config.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate config: %s", err)
+	}
+
+	err = os.MkdirAll(p.config.Path, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create directory %s: %s", p.config.Path, err)
+	}
+
+	// verify that directory is writable
+	file, err := os.CreateTemp(p.config.Path, ".check-*")
+	if err != nil {
+		return fmt.Errorf("directory %s is not writable: %s", p.config.Path, err)
+	}
+	file.Close()
+
+	return os.Remove(file.Name())
+}
+
+func (p *Parquet) Setup() error {
+	if err := p.Check(); err != nil {
+		return err
+	}
+
+	p.streams = make(map[string]*writer)
+
+	return nil
+}
+
+func (p *Parquet) Create(stream protocol.Stream) error {
+	schema, columns, err := toArrowSchema(stream.Schema())
+	if err != nil {
+		return err
+	}
+
+	directory := filepath.Join(p.config.Path, stream.Namespace(), stream.Name())
+	if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directory %s: %s", directory, err)
+	}
+
+	if err := salvage(directory); err != nil {
+		return fmt.Errorf("failed to salvage files of stream %s: %s", stream.ID(), err)
+	}
+
+	p.streams[stream.ID()] = &writer{
+		directory: directory,
+		schema:    schema,
+		columns:   columns,
+		config:    p.config,
+	}
+
+	return nil
+}
+
+func (p *Parquet) Write(channel <-chan types.Record) error {
+	for record := range channel {
+		id := utils.StreamIdentifier(record.Stream, record.Namespace)
+		writer, found := p.streams[id]
+		if !found {
+			return fmt.Errorf("stream %s was not created", id)
+		}
+
+		if err := writer.append(record.Data); err != nil {
+			return fmt.Errorf("failed to write record of stream %s: %s", id, err)
+		}
+	}
+
+	// commit open files; makes records of this write durable while files keep rolling
+	// on max rows
+	for id, writer := range p.streams {
+		if err := writer.commit(); err != nil {
+			return fmt.Errorf("failed to commit parquet file of stream %s: %s", id, err)
+		}
+	}
+
+	return nil
+}
+
+// Close finalizes open files once every record has been written
+func (p *Parquet) Close() error {
+	for id, writer := range p.streams {
+		if err := writer.close(); err != nil {
+			return fmt.Errorf("failed to close parquet file of stream %s: %s", id, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/apache/arrow/go/v16/parquet/file"
+	"github.com/apache/arrow/go/v16/parquet/pqarrow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gear5sh/gear5/types"
+)
+
+func newStream() *types.ConfiguredStream {
+	return &types.ConfiguredStream{
+		Stream: &types.Stream{
+			Name:      "users",
+			Namespace: "public",
+			Schema: &types.TypeSchema{Properties: map[string]*types.Property{
+				"id":   {Type: []types.DataType{types.INT64}},
+				"name": {Type: []types.DataType{types.STRING, types.NULL}},
+			}},
+		},
+	}
+}
+
+func newParquet(t *testing.T, path string, rowGroupSize, maxRowsPerFile int) *Parquet {
+	p := &Parquet{}
+	config := p.Config().(*Config)
+	config.Path = path
+	config.RowGroupSize = rowGroupSize
+	config.MaxRowsPerFile = maxRowsPerFile
+
+	require.NoError(t, p.Setup())
+	require.NoError(t, p.Create(newStream()))
+
+	return p
+}
+
+// write passes records of ids to a single Write call alike a segment of the write command
+func write(t *testing.T, p *Parquet, ids ...int) {
+	channel := make(chan types.Record, len(ids))
+	for _, id := range ids {
+		channel <- types.Record{Namespace: "public", Stream: "users", Data: map[string]any{"id": id, "name": "user"}}
+	}
+	close(channel)
+
+	require.NoError(t, p.Write(channel))
+}
+
+// files returns ids written per finalized file of stream in order of files
+func files(t *testing.T, path string) [][]int64 {
+	paths, err := filepath.Glob(filepath.Join(path, "public", "users", "*.parquet"))
+	require.NoError(t, err)
+	sort.Strings(paths)
+
+	written := [][]int64{}
+	for _, path := range paths {
+		reader, err := file.OpenParquetFile(path, false)
+		require.NoError(t, err)
+
+		fr, err := pqarrow.NewFileReader(reader, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+		require.NoError(t, err)
+
+		table, err := fr.ReadTable(context.Background())
+		require.NoError(t, err)
+
+		ids := []int64{}
+		column := table.Column(table.Schema().FieldIndices("id")[0])
+		for _, chunk := range column.Data().Chunks() {
+			for i := 0; i < chunk.Len(); i++ {
+				ids = append(ids, chunk.GetOneForMarshal(i).(int64))
+			}
+		}
+		written = append(written, ids)
+
+		table.Release()
+		require.NoError(t, reader.Close())
+	}
+
+	return written
+}
+
+func tmpFiles(t *testing.T, path string) []string {
+	paths, err := filepath.Glob(filepath.Join(path, "public", "users", "*.parquet.tmp"))
+	require.NoError(t, err)
+
+	return paths
+}
+
+func TestToArrowSchema(t *testing.T) {
+	precision, scale := 10, 2
+	schema, columns, err := toArrowSchema(&types.TypeSchema{Properties: map[string]*types.Property{
+		"id":         {Type: []types.DataType{types.INT64}},
+		"amount":     {Type: []types.DataType{types.DECIMAL, types.NULL}, Precision: &precision, Scale: &scale},
+		"balance":    {Type: []types.DataType{types.DECIMAL}},
+		"born_on":    {Type: []types.DataType{types.DATE}},
+		"opens_at":   {Type: []types.DataType{types.TIME}},
+		"created_at": {Type: []types.DataType{types.TIMESTAMP}},
+		"score":      {Type: []types.DataType{types.FLOAT64}},
+		"active":     {Type: []types.DataType{types.BOOL}},
+		"tags":       {Type: []types.DataType{types.ARRAY}},
+	}})
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, column := range columns {
+		names = append(names, column.name)
+	}
+	assert.Equal(t, []string{"active", "amount", "balance", "born_on", "created_at", "id", "opens_at", "score", "tags"}, names)
+
+	expected := []arrow.DataType{
+		arrow.FixedWidthTypes.Boolean,
+		&arrow.Decimal128Type{Precision: 10, Scale: 2},
+		arrow.BinaryTypes.String,
+		arrow.FixedWidthTypes.Date32,
+		arrow.FixedWidthTypes.Timestamp_us,
+		arrow.PrimitiveTypes.Int64,
+		arrow.FixedWidthTypes.Time64us,
+		arrow.PrimitiveTypes.Float64,
+		arrow.BinaryTypes.String,
+	}
+	for i, field := range schema.Fields() {
+		assert.True(t, arrow.TypeEqual(expected[i], field.Type), "%s: %s", field.Name, field.Type)
+		assert.True(t, field.Nullable)
+	}
+
+	_, _, err = toArrowSchema(&types.TypeSchema{})
+	assert.Error(t, err)
+}
+
+func TestWriteRollsOnMaxRows(t *testing.T) {
+	path := t.TempDir()
+	p := newParquet(t, path, 2, 3)
+
+	write(t, p, 1, 2, 3, 4, 5, 6, 7)
+	require.NoError(t, p.Close())
+
+	// files roll once a row group takes them past max rows
+	assert.Equal(t, [][]int64{{1, 2, 3, 4}, {5, 6, 7}}, files(t, path))
+	assert.Empty(t, tmpFiles(t, path))
+}
+
+func TestWriteKeepsFileAcrossWrites(t *testing.T) {
+	path := t.TempDir()
+	p := newParquet(t, path, 100, 1000)
+
+	write(t, p, 1, 2)
+	write(t, p, 3, 4)
+	write(t, p, 5)
+
+	assert.Empty(t, files(t, path))
+	assert.Len(t, tmpFiles(t, path), 1)
+
+	require.NoError(t, p.Close())
+	assert.Equal(t, [][]int64{{1, 2, 3, 4, 5}}, files(t, path))
+	assert.Empty(t, tmpFiles(t, path))
+}
+
+func TestSalvageInterruptedWrite(t *testing.T) {
+	path := t.TempDir()
+	p := newParquet(t, path, 2, 1000)
+
+	write(t, p, 1, 2, 3)
+
+	// a row group written past the last commit is cut by salvage
+	w := p.streams["public.users"]
+	for _, id := range []int{4, 5} {
+		require.NoError(t, w.append(map[string]any{"id": id, "name": "user"}))
+	}
+	require.NoError(t, w.file.Sync())
+
+	newParquet(t, path, 2, 1000)
+	assert.Equal(t, [][]int64{{1, 2, 3}}, files(t, path))
+	assert.Empty(t, tmpFiles(t, path))
+}
+
+func TestSalvageRemovesUncommittedFile(t *testing.T) {
+	path := t.TempDir()
+	directory := filepath.Join(path, "public", "users")
+	require.NoError(t, os.MkdirAll(directory, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "1.parquet.tmp"), []byte("PAR1 torn"), 0644))
+
+	newParquet(t, path, 2, 1000)
+	assert.Empty(t, files(t, path))
+	assert.Empty(t, tmpFiles(t, path))
+}
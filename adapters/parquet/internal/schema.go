@@ -0,0 +1,200 @@
+package adapter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/decimal128"
+	"github.com/goccy/go-json"
+
+	"github.com/gear5sh/gear5/types"
+	"github.com/gear5sh/gear5/typeutils"
+)
+
+type column struct {
+	name     string
+	property *types.Property
+}
+
+// Convert TypeSchema into Arrow Schema; columns are sorted to keep files of a stream uniform
+func toArrowSchema(schema *types.TypeSchema) (*arrow.Schema, []column, error) {
+	if schema == nil || len(schema.Properties) == 0 {
+		return nil, nil, fmt.Errorf("stream has no type schema")
+	}
+
+	columns := []column{}
+	for name, property := range schema.Properties {
+		columns = append(columns, column{name: name, property: property})
+	}
+
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].name < columns[j].name
+	})
+
+	fields := []arrow.Field{}
+	for _, column := range columns {
+		fields = append(fields, arrow.Field{
+			Name: column.name,
+			Type: toArrowType(column.property),
+			// CDC deletes only carry key columns; hence all fields are kept nullable
+			Nullable: true,
+		})
+	}
+
+	return arrow.NewSchema(fields, nil), columns, nil
+}
+
+func toArrowType(property *types.Property) arrow.DataType {
+	switch property.DataType() {
+	case types.DECIMAL:
+		// decimals beyond arrow precision or without precision are kept as strings
+		if property.Precision != nil && *property.Precision > 0 && *property.Precision <= 38 {
+			scale := 0
+			if property.Scale != nil {
+				scale = *property.Scale
+			}
+
+			return &arrow.Decimal128Type{Precision: int32(*property.Precision), Scale: int32(scale)}
+		}
+
+		return arrow.BinaryTypes.String
+	case types.DATE:
+		return arrow.FixedWidthTypes.Date32
+	case types.TIME:
+		return arrow.FixedWidthTypes.Time64us
+	case types.INT64:
+		return arrow.PrimitiveTypes.Int64
+	case types.FLOAT64:
+		return arrow.PrimitiveTypes.Float64
+	case types.BOOL:
+		return arrow.FixedWidthTypes.Boolean
+	case types.TIMESTAMP:
+		return arrow.FixedWidthTypes.Timestamp_us
+	default:
+		// strings, objects and arrays are written as (json encoded) strings
+		return arrow.BinaryTypes.String
+	}
+}
+
+// Append a record value into respective column builder
+func appendValue(builder array.Builder, property *types.Property, value any) error {
+	if value == nil {
+		builder.AppendNull()
+		return nil
+	}
+
+	datatype := property.DataType()
+	switch builder := builder.(type) {
+	case *array.Int64Builder:
+		reformatted, err := typeutils.ReformatInt64(value)
+		if err != nil {
+			return err
+		}
+
+		builder.Append(reformatted)
+	case *array.Float64Builder:
+		reformatted, err := typeutils.ReformatFloat64(value)
+		if err != nil {
+			return err
+		}
+
+		builder.Append(reformatted.(float64))
+	case *array.BooleanBuilder:
+		reformatted, err := typeutils.ReformatValue(types.BOOL, value)
+		if err != nil {
+			return err
+		}
+
+		builder.Append(reformatted.(bool))
+	case *array.TimestampBuilder:
+		reformatted, err := typeutils.ReformatDate(value)
+		if err != nil {
+			return err
+		}
+
+		builder.Append(arrow.Timestamp(reformatted.UTC().UnixMicro()))
+	case *array.Decimal128Builder:
+		decimal, err := typeutils.ReformatDecimal(value)
+		if err != nil {
+			return err
+		}
+
+		typ := builder.Type().(*arrow.Decimal128Type)
+		number, err := decimal128.FromString(decimal, typ.Precision, typ.Scale)
+		if err != nil {
+			return err
+		}
+
+		builder.Append(number)
+	case *array.Date32Builder:
+		reformatted, err := typeutils.ReformatDate(value)
+		if err != nil {
+			return err
+		}
+
+		builder.Append(arrow.Date32FromTime(reformatted))
+	case *array.Time64Builder:
+		text, err := typeutils.ReformatTime(value)
+		if err != nil {
+			return err
+		}
+
+		// zone of timetz values is dropped
+		if i := strings.IndexAny(text[min(len(text), 8):], "+-"); i >= 0 {
+			text = text[:8+i]
+		}
+
+		clock, err := time.Parse("15:04:05.999999999", text)
+		if err != nil {
+			return fmt.Errorf("failed to parse time %s: %s", text, err)
+		}
+
+		midnight := time.Date(clock.Year(), clock.Month(), clock.Day(), 0, 0, 0, 0, time.UTC)
+		builder.Append(arrow.Time64(clock.Sub(midnight).Microseconds()))
+	case *array.StringBuilder:
+		switch datatype {
+		case types.OBJECT, types.ARRAY, types.JSON, types.UNKNOWN:
+			if _, isString := value.(string); !isString {
+				encoded, err := json.Marshal(value)
+				if err != nil {
+					return err
+				}
+
+				builder.Append(string(encoded))
+				return nil
+			}
+		}
+
+		switch value := value.(type) {
+		case time.Time:
+			builder.Append(value.UTC().Format(time.RFC3339Nano))
+		case []byte:
+			builder.Append(string(value))
+		default:
+			if datatype == types.DECIMAL {
+				decimal, err := typeutils.ReformatDecimal(value)
+				if err != nil {
+					return err
+				}
+
+				builder.Append(decimal)
+				return nil
+			}
+
+			reformatted, err := typeutils.ReformatValue(types.STRING, value)
+			if err != nil {
+				return err
+			}
+
+			builder.Append(reformatted.(string))
+		}
+	default:
+		return fmt.Errorf("unsupported arrow builder %T", builder)
+	}
+
+	return nil
+}
@@ -0,0 +1,299 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/apache/arrow/go/v16/parquet"
+	"github.com/apache/arrow/go/v16/parquet/file"
+	"github.com/apache/arrow/go/v16/parquet/pqarrow"
+
+	"github.com/gear5sh/gear5/logger"
+	"github.com/gear5sh/gear5/types"
+)
+
+// writer writes a single stream into rolling parquet files; a file stays open across
+// writes until it reaches max rows or the adapter is closed
+type writer struct {
+	directory string
+	schema    *arrow.Schema
+	columns   []column
+	config    *Config
+
+	file     *os.File
+	pw       *file.Writer
+	ctx      context.Context // arrow write context of pw
+	builder  *array.RecordBuilder
+	buffered int // rows in builder
+	rows     int // rows in current file
+}
+
+func (w *writer) append(data types.RecordData) error {
+	if w.builder == nil {
+		w.builder = array.NewRecordBuilder(memory.DefaultAllocator, w.schema)
+	}
+
+	for i, column := range w.columns {
+		err := appendValue(w.builder.Field(i), column.property, data[column.name])
+		if err != nil {
+			return fmt.Errorf("column[%s]: %s", column.name, err)
+		}
+	}
+
+	w.buffered++
+	if w.buffered >= w.config.RowGroupSize {
+		return w.flush()
+	}
+
+	return nil
+}
+
+// Write buffered rows as a row group; rolls file once it reaches max rows
+func (w *writer) flush() error {
+	if w.buffered == 0 {
+		return nil
+	}
+
+	if w.pw == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+
+	record := w.builder.NewRecord()
+	defer record.Release()
+
+	if err := w.writeRowGroup(record); err != nil {
+		return err
+	}
+
+	w.rows += w.buffered
+	w.buffered = 0
+
+	if w.rows >= w.config.MaxRowsPerFile {
+		return w.closeFile()
+	}
+
+	return nil
+}
+
+// commit makes every row appended so far durable without closing current file; a footer
+// covering all row groups is written so that the file can be salvaged up to here
+func (w *writer) commit() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	if w.pw == nil {
+		return nil
+	}
+
+	if err := w.pw.FlushWithFooter(); err != nil {
+		return err
+	}
+
+	return w.file.Sync()
+}
+
+func (w *writer) open() error {
+	path := filepath.Join(w.directory, fmt.Sprintf("%d.parquet.tmp", time.Now().UnixNano()))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	props := parquet.NewWriterProperties(parquet.WithCompression(w.config.Codec()))
+	arrowProps := pqarrow.DefaultWriterProps()
+
+	schema, err := pqarrow.ToParquet(w.schema, props, arrowProps)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.file = f
+	w.pw = file.NewParquetWriter(syncedFile{f}, schema.Root(), file.WithWriterProps(props))
+	w.ctx = pqarrow.NewArrowWriteContext(context.Background(), &arrowProps)
+	w.rows = 0
+
+	return nil
+}
+
+// writeRowGroup writes record as a single row group; columns are flat and nullable
+func (w *writer) writeRowGroup(record arrow.Record) error {
+	rgw := w.pw.AppendRowGroup()
+	for i, column := range record.Columns() {
+		cw, err := rgw.NextColumn()
+		if err != nil {
+			return err
+		}
+
+		levels := make([]int16, column.Len())
+		for j := range levels {
+			if column.IsValid(j) {
+				levels[j] = 1
+			}
+		}
+
+		if err := pqarrow.WriteArrowToColumn(w.ctx, cw, column, levels, nil, true); err != nil {
+			return fmt.Errorf("column[%s]: %s", w.columns[i].name, err)
+		}
+	}
+
+	return rgw.Close()
+}
+
+// closeFile writes parquet footer and moves the temporary file to its final name
+func (w *writer) closeFile() error {
+	if w.pw == nil {
+		return nil
+	}
+
+	// parquet writer closes underlying file as well
+	if err := w.pw.Close(); err != nil {
+		return err
+	}
+
+	tmp := w.file.Name()
+	final := tmp[:len(tmp)-len(".tmp")]
+	if err := os.Rename(tmp, final); err != nil {
+		return err
+	}
+
+	logger.Infof("Written %d rows to %s", w.rows, final)
+
+	w.pw = nil
+	w.file = nil
+	w.rows = 0
+
+	return nil
+}
+
+// close flushes buffered rows and closes current file
+func (w *writer) close() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	return w.closeFile()
+}
+
+// syncedFile flushes file contents to disk before closing
+type syncedFile struct {
+	*os.File
+}
+
+func (f syncedFile) Close() error {
+	if err := f.Sync(); err != nil {
+		f.File.Close()
+		return err
+	}
+
+	return f.File.Close()
+}
+
+// salvage finalizes files of directory left open by an interrupted write; a file is cut
+// after its last footer i.e. to rows committed before the interruption
+func salvage(directory string) error {
+	paths, err := filepath.Glob(filepath.Join(directory, "*.parquet.tmp"))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		size, err := lastFooter(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %s", path, err)
+		}
+
+		if size == 0 {
+			logger.Warnf("Removing %s holding no committed rows", path)
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := os.Truncate(path, size); err != nil {
+			return err
+		}
+
+		final := path[:len(path)-len(".tmp")]
+		if err := os.Rename(path, final); err != nil {
+			return err
+		}
+
+		logger.Infof("Salvaged %s left by an interrupted write", final)
+	}
+
+	return nil
+}
+
+// lastFooter returns size of file up to its last valid footer; 0 if it has none
+func lastFooter(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	magic := []byte("PAR1")
+	buffer := make([]byte, 1<<20)
+	for end := info.Size(); end > 0; {
+		start := max(0, end-int64(len(buffer)))
+		chunk := buffer[:end-start]
+		if _, err := f.ReadAt(chunk, start); err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		for limit := len(chunk); ; {
+			i := bytes.LastIndex(chunk[:limit], magic)
+			if i < 0 {
+				break
+			}
+
+			size := start + int64(i+len(magic))
+			if validFooter(f, size) {
+				return size, nil
+			}
+
+			limit = i + len(magic) - 1
+		}
+
+		if start == 0 {
+			break
+		}
+
+		// chunks overlap so that magic bytes split across them are found
+		end = start + int64(len(magic)-1)
+	}
+
+	return 0, nil
+}
+
+// validFooter tells if file cut at size ends with a readable footer
+func validFooter(f *os.File, size int64) (valid bool) {
+	// bytes of row groups may look alike a footer and fail parsing in any way
+	defer func() {
+		if recover() != nil {
+			valid = false
+		}
+	}()
+
+	_, err := file.NewParquetReader(io.NewSectionReader(f, 0, size))
+	return err == nil
+}
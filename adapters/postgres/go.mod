@@ -0,0 +1,12 @@
+module github.com/gear5sh/gear5/adapters/postgres
+
+go 1.22
+
+toolchain go1.22.3
+
+require (
+	github.com/gear5sh/gear5 v0.0.0-20230727050722-6795340c7033
+	github.com/jackc/pgx/v5 v5.5.4
+	github.com/lib/pq v1.10.9
+	github.com/stretchr/testify v1.9.0
+)
@@ -0,0 +1,163 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gear5sh/gear5/logger"
+	"github.com/gear5sh/gear5/protocol"
+	"github.com/gear5sh/gear5/types"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	existingColumnsTmpl = `SELECT column_name FROM information_schema.columns WHERE table_schema = $1 AND table_name = $2`
+	// key columns of unique indexes usable as conflict target i.e. neither partial, on expressions nor deferrable
+	uniqueKeysTmpl = `SELECT array_agg(a.attname::text) FROM pg_index i
+	JOIN pg_attribute a ON a.attrelid = i.indrelid AND array_position(i.indkey::int2[], a.attnum) <= i.indnkeyatts
+	WHERE i.indrelid = to_regclass($1) AND i.indisunique AND i.indisvalid AND i.indimmediate AND i.indpred IS NULL AND i.indexprs IS NULL
+	GROUP BY i.indexrelid`
+)
+
+var dataTypeToPg = map[types.DataType]string{
+	types.INT64:     "BIGINT",
+	types.FLOAT64:   "DOUBLE PRECISION",
+	types.STRING:    "TEXT",
+	types.BOOL:      "BOOLEAN",
+	types.OBJECT:    "JSONB",
+	types.ARRAY:     "JSONB",
+	types.TIMESTAMP: "TIMESTAMPTZ",
+	types.DECIMAL:   "NUMERIC",
+	types.DATE:      "DATE",
+	types.TIME:      "TIME",
+	types.JSON:      "JSONB",
+}
+
+func pgType(property *types.Property) string {
+	datatype := property.DataType()
+	if datatype == types.DECIMAL && property.Precision != nil {
+		scale := 0
+		if property.Scale != nil {
+			scale = *property.Scale
+		}
+
+		return fmt.Sprintf("NUMERIC(%d,%d)", *property.Precision, scale)
+	}
+
+	if typ, found := dataTypeToPg[datatype]; found {
+		return typ
+	}
+
+	return "TEXT"
+}
+
+// columnDefinitions returns column definitions of columns; all columns of stream if none passed
+func (t *table) columnDefinitions(columns ...string) []string {
+	if len(columns) == 0 {
+		columns = t.columns
+	}
+
+	definitions := []string{}
+	for _, column := range columns {
+		definitions = append(definitions, fmt.Sprintf("%s %s", pgx.Identifier{column}.Sanitize(), pgType(t.properties[column])))
+	}
+
+	return definitions
+}
+
+// Execute DDL of an action on destination table of a stream
+func (p *Postgres) execute(action types.Action, t *table, columns ...string) error {
+	statements := []string{}
+	switch action {
+	case types.CREATE:
+		definitions := t.columnDefinitions()
+		if len(t.primaryKey) > 0 {
+			definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", quoteColumns(t.primaryKey)))
+		}
+
+		statements = append(statements,
+			fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", pgx.Identifier{t.identifier[0]}.Sanitize()),
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", t.identifier.Sanitize(), strings.Join(definitions, ", ")))
+	case types.ALTER:
+		for _, definition := range t.columnDefinitions(columns...) {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s", t.identifier.Sanitize(), definition))
+		}
+	case types.TRUNCATE:
+		statements = append(statements, fmt.Sprintf("TRUNCATE TABLE %s", t.identifier.Sanitize()))
+	case types.DROP:
+		statements = append(statements, fmt.Sprintf("DROP TABLE IF EXISTS %s", t.identifier.Sanitize()))
+	default:
+		return fmt.Errorf("unsupported action %s", action)
+	}
+
+	ctx := context.Background()
+	tx, err := p.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, statement := range statements {
+		logger.Debugf("Executing %s: %s", action, statement)
+
+		if _, err := tx.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("failed to execute %s on %s: %s", action, t.identifier.Sanitize(), err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func quoteColumns(columns []string) string {
+	quoted := []string{}
+	for _, column := range columns {
+		quoted = append(quoted, pgx.Identifier{column}.Sanitize())
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// Act applies actions emitted by drivers on destination table
+func (p *Postgres) Act(stream protocol.Stream, action *types.ActionRow) error {
+	t, found := p.tables[stream.ID()]
+	if !found {
+		return fmt.Errorf("stream %s was not created", stream.ID())
+	}
+
+	switch action.Type {
+	case types.ALTER:
+		columns := []string{}
+		for column, property := range action.Columns {
+			if existing, found := t.properties[column]; !found {
+				t.columns = append(t.columns, column)
+				columns = append(columns, column)
+			} else if existing.DataType() != property.DataType() {
+				logger.Warnf("column %s of %s changed type to %s; destination type is kept", column, t.identifier.Sanitize(), property.DataType())
+				continue
+			}
+
+			t.properties[column] = property
+		}
+
+		sort.Strings(t.columns)
+		if len(columns) == 0 {
+			return nil
+		}
+
+		sort.Strings(columns)
+		return p.execute(types.ALTER, t, columns...)
+	case types.TRUNCATE:
+		if err := p.execute(action.Type, t); err != nil {
+			return err
+		}
+
+		t.truncated()
+		return nil
+	case types.DROP:
+		return p.execute(action.Type, t)
+	default:
+		return fmt.Errorf("unsupported action %s", action.Type)
+	}
+}
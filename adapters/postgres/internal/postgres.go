@@ -0,0 +1,237 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gear5sh/gear5/logger"
+	"github.com/gear5sh/gear5/protocol"
+	"github.com/gear5sh/gear5/types"
+	"github.com/jackc/pgx/v5"
+)
+
+type Postgres struct {
+	conn   *pgx.Conn
+	config *Config           // postgres adapter connection config
+	tables map[string]*table // destination tables by stream identifier
+}
+
+// table holds destination details of a configured stream
+type table struct {
+	stream     protocol.Stream
+	identifier pgx.Identifier
+	columns    []string // sorted columns of stream schema
+	properties map[string]*types.Property
+	primaryKey []string
+	upsert     bool               // merge rows on primary key instead of appending; see truncated
+	rows       []types.RecordData // rows buffered for next batch
+}
+
+func (p *Postgres) Config() any {
+	p.config = &Config{}
+
+	return p.config
+}
+
+func (p *Postgres) Spec() any {
+	return Config{}
+}
+
+func (p *Postgres) Type() string {
+	return "Postgres"
+}
+
+func (p *Postgres) Check() error {
+	err := p.config.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate config: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, p.config.Connection.String())
+	if err != nil {
+		return fmt.Errorf("failed to connect database: %s", err)
+	}
+
+	// force a connection and test that it worked
+	err = conn.Ping(ctx)
+	if err != nil {
+		conn.Close(ctx)
+		return fmt.Errorf("failed to ping database: %s", err)
+	}
+
+	p.conn = conn
+
+	return nil
+}
+
+func (p *Postgres) Setup() error {
+	if err := p.Check(); err != nil {
+		return err
+	}
+
+	p.tables = make(map[string]*table)
+
+	return nil
+}
+
+// Close flushes rows buffered for every table and closes connection
+func (p *Postgres) Close() error {
+	if p.conn == nil {
+		return nil
+	}
+
+	for _, t := range p.tables {
+		if err := p.flush(t); err != nil {
+			return err
+		}
+	}
+
+	if err := p.conn.Close(context.Background()); err != nil {
+		return fmt.Errorf("failed to close connection with postgres: %s", err)
+	}
+
+	p.conn = nil
+
+	return nil
+}
+
+func (p *Postgres) Create(stream protocol.Stream) error {
+	if stream.Schema() == nil || len(stream.Schema().Properties) == 0 {
+		return fmt.Errorf("stream has no type schema")
+	}
+
+	namespace := stream.Namespace()
+	if namespace == "" {
+		namespace = p.config.DefaultSchema
+	}
+
+	t := &table{
+		stream:     stream,
+		identifier: pgx.Identifier{namespace, stream.Name()},
+		properties: make(map[string]*types.Property),
+		primaryKey: stream.GetStream().SourceDefinedPrimaryKey.Array(),
+	}
+
+	for column, property := range stream.Schema().Properties {
+		t.columns = append(t.columns, column)
+		t.properties[column] = property
+	}
+
+	sort.Strings(t.columns)
+	sort.Strings(t.primaryKey)
+
+	// full refresh is merged as well until truncated since a resumed load rewrites rows
+	// committed past its last checkpoint
+	t.upsert = len(t.primaryKey) > 0
+	if stream.GetSyncMode() != types.FULLREFRESH && len(t.primaryKey) == 0 {
+		logger.Warnf("stream %s has no primary key; rows will be appended", stream.ID())
+	}
+
+	existing, err := p.existingColumns(t)
+	if err != nil {
+		return err
+	}
+
+	if len(existing) == 0 {
+		err = p.execute(types.CREATE, t)
+	} else {
+		missing := []string{}
+		for _, column := range t.columns {
+			if _, found := existing[column]; !found {
+				missing = append(missing, column)
+			}
+		}
+
+		if len(missing) > 0 {
+			err = p.execute(types.ALTER, t, missing...)
+		}
+
+		if err == nil && t.upsert {
+			err = p.validateConflictTarget(t)
+			if err != nil && stream.GetSyncMode() == types.FULLREFRESH {
+				logger.Warnf("%s; rows of a resumed full refresh are appended", err)
+				t.upsert, err = false, nil
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	p.tables[stream.ID()] = t
+
+	return nil
+}
+
+func (p *Postgres) existingColumns(t *table) (map[string]struct{}, error) {
+	rows, err := p.conn.Query(context.Background(), existingColumnsTmpl, t.identifier[0], t.identifier[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch columns of %s: %s", t.identifier.Sanitize(), err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]struct{})
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+
+		columns[column] = struct{}{}
+	}
+
+	return columns, rows.Err()
+}
+
+// validateConflictTarget fails if existing table has no primary key or unique index on
+// primary key of stream; upserts can't resolve conflicts without one
+func (p *Postgres) validateConflictTarget(t *table) error {
+	rows, err := p.conn.Query(context.Background(), uniqueKeysTmpl, t.identifier.Sanitize())
+	if err != nil {
+		return fmt.Errorf("failed to fetch unique keys of %s: %s", t.identifier.Sanitize(), err)
+	}
+	defer rows.Close()
+
+	keys := [][]string{}
+	for rows.Next() {
+		var key []string
+		if err := rows.Scan(&key); err != nil {
+			return err
+		}
+
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return t.conflictTarget(keys)
+}
+
+// truncated appends rows of full refresh once destination table is emptied; rows of a single
+// load don't conflict
+func (t *table) truncated() {
+	if t.stream.GetSyncMode() == types.FULLREFRESH {
+		t.upsert = false
+	}
+}
+
+// conflictTarget fails unless one of unique keys of destination table is primary key of stream
+func (t *table) conflictTarget(keys [][]string) error {
+	for _, key := range keys {
+		sort.Strings(key)
+		if slices.Equal(key, t.primaryKey) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("existing table %s has no primary key or unique index on (%s) required to upsert rows; add one or sync stream with full refresh",
+		t.identifier.Sanitize(), strings.Join(t.primaryKey, ", "))
+}
@@ -0,0 +1,64 @@
+package adapter
+
+import (
+	"testing"
+
+	"github.com/gear5sh/gear5/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConflictTarget(t *testing.T) {
+	users := &table{identifier: pgx.Identifier{"public", "users"}, primaryKey: []string{"id", "tenant_id"}}
+
+	// pre-existing table without primary key
+	assert.ErrorContains(t, users.conflictTarget(nil), "no primary key or unique index on (id, tenant_id)")
+	assert.Error(t, users.conflictTarget([][]string{{"id"}, {"email"}}))
+
+	assert.NoError(t, users.conflictTarget([][]string{{"email"}, {"tenant_id", "id"}}))
+}
+
+func TestFullRefreshMergedUntilTruncated(t *testing.T) {
+	stream := types.NewStream("users", "public")
+	stream.WithPrimaryKey("id")
+
+	for _, mode := range []types.SyncMode{types.FULLREFRESH, types.CDC} {
+		configured := stream.Wrap(100)
+		configured.SyncMode = mode
+		users := &table{stream: configured, primaryKey: []string{"id"}, upsert: true}
+
+		users.truncated()
+		assert.Equal(t, mode != types.FULLREFRESH, users.upsert, mode)
+	}
+}
+
+func TestDeduplicateKeepsOmittedColumns(t *testing.T) {
+	users := &table{primaryKey: []string{"id"}, rows: []types.RecordData{
+		{"id": 1, "name": "a", "bio": "long", "_cdc_op": "update"},
+		{"id": 2, "name": "b", "bio": nil},
+		{"id": 1, "name": "c", "_cdc_op": "delete", "_cdc_deleted_at": "2024-01-01"},
+		{"id": 1, "name": "d", "_cdc_op": "insert"},
+	}}
+
+	assert.Equal(t, []types.RecordData{
+		{"id": 1, "name": "d", "bio": "long", "_cdc_op": "insert"},
+		{"id": 2, "name": "b", "bio": nil},
+	}, users.deduplicate())
+}
+
+func TestGroupByColumns(t *testing.T) {
+	users := &table{columns: []string{"_cdc_deleted_at", "_cdc_op", "bio", "id", "name"}, primaryKey: []string{"id"}}
+
+	columnSets, groups := users.groupByColumns([]types.RecordData{
+		{"id": 1, "name": "a", "bio": nil, "_cdc_op": "insert"},
+		{"id": 2, "name": "b", "_cdc_op": "update"},
+		{"id": 3, "name": "c", "bio": "long", "_cdc_op": "update"},
+	})
+
+	assert.Equal(t, [][]string{{"_cdc_deleted_at", "_cdc_op", "bio", "id", "name"}, {"_cdc_deleted_at", "_cdc_op", "id", "name"}}, columnSets)
+	assert.Equal(t, [][]types.RecordData{
+		{{"id": 1, "name": "a", "bio": nil, "_cdc_op": "insert"}, {"id": 3, "name": "c", "bio": "long", "_cdc_op": "update"}},
+		{{"id": 2, "name": "b", "_cdc_op": "update"}},
+	}, groups)
+	assert.Equal(t, `ON CONFLICT ("id") DO UPDATE SET "_cdc_deleted_at" = EXCLUDED."_cdc_deleted_at", "_cdc_op" = EXCLUDED."_cdc_op", "name" = EXCLUDED."name"`, users.onConflict(columnSets[1]))
+}
@@ -0,0 +1,299 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gear5sh/gear5/pkg/jdbc"
+	"github.com/gear5sh/gear5/types"
+	"github.com/gear5sh/gear5/typeutils"
+	"github.com/gear5sh/gear5/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const stagingTable = "gear5_staging"
+
+func (p *Postgres) Write(channel <-chan types.Record) error {
+	for record := range channel {
+		id := utils.StreamIdentifier(record.Stream, record.Namespace)
+		t, found := p.tables[id]
+		if !found {
+			return fmt.Errorf("stream %s was not created", id)
+		}
+
+		t.rows = append(t.rows, record.Data)
+		if len(t.rows) >= p.config.BatchSize {
+			if err := p.flush(t); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, t := range p.tables {
+		if err := p.flush(t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flush writes buffered rows of a table in a single transaction
+func (p *Postgres) flush(t *table) error {
+	if len(t.rows) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	tx, err := p.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if t.upsert {
+		err = p.merge(ctx, tx, t)
+	} else {
+		err = copyRows(ctx, tx, t.identifier, t, t.rows)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write into %s: %s", t.identifier.Sanitize(), err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	t.rows = t.rows[:0]
+
+	return nil
+}
+
+// merge copies rows into a staging table and upserts them on primary key; rows are merged
+// in groups of columns they carry so that omitted columns e.g. unchanged toasted values
+// aren't overwritten
+func (p *Postgres) merge(ctx context.Context, tx pgx.Tx, t *table) error {
+	stage := pgx.Identifier{stagingTable}
+	_, err := tx.Exec(ctx, fmt.Sprintf("CREATE TEMP TABLE %s (%s) ON COMMIT DROP", stage.Sanitize(), strings.Join(t.columnDefinitions(), ", ")))
+	if err != nil {
+		return err
+	}
+
+	columnSets, groups := t.groupByColumns(t.deduplicate())
+	for i, rows := range groups {
+		if i > 0 {
+			if _, err := tx.Exec(ctx, fmt.Sprintf("TRUNCATE TABLE %s", stage.Sanitize())); err != nil {
+				return err
+			}
+		}
+
+		if err := copyRows(ctx, tx, stage, t, rows); err != nil {
+			return err
+		}
+
+		if err := p.mergeStaged(ctx, tx, t, stage, columnSets[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeStaged upserts staged rows setting only columns
+func (p *Postgres) mergeStaged(ctx context.Context, tx pgx.Tx, t *table, stage pgx.Identifier, columns []string) error {
+	quotedColumns := quoteColumns(columns)
+	upsert := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", t.identifier.Sanitize(), quotedColumns, quotedColumns, stage.Sanitize())
+
+	// rows of CDC streams carrying deleted at are processed as deletes
+	if _, found := t.properties[jdbc.CDCDeletedAt]; found {
+		deletedAt := pgx.Identifier{jdbc.CDCDeletedAt}.Sanitize()
+		deletes := ""
+		if p.config.HardDelete {
+			conditions := []string{}
+			for _, column := range t.primaryKey {
+				quoted := pgx.Identifier{column}.Sanitize()
+				conditions = append(conditions, fmt.Sprintf("t.%s = s.%s", quoted, quoted))
+			}
+
+			deletes = fmt.Sprintf("DELETE FROM %s t USING %s s WHERE %s AND s.%s IS NOT NULL",
+				t.identifier.Sanitize(), stage.Sanitize(), strings.Join(conditions, " AND "), deletedAt)
+		} else {
+			// only mark deleted; deletes carry no values other than keys
+			marked := append([]string{}, t.primaryKey...)
+			for _, column := range t.columns {
+				if _, found := jdbc.CDCColumns[column]; found {
+					marked = append(marked, column)
+				}
+			}
+
+			deletes = fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s WHERE %s IS NOT NULL %s",
+				t.identifier.Sanitize(), quoteColumns(marked), quoteColumns(marked), stage.Sanitize(), deletedAt, t.onConflict(marked))
+		}
+
+		if _, err := tx.Exec(ctx, deletes); err != nil {
+			return err
+		}
+
+		upsert = fmt.Sprintf("%s WHERE %s IS NULL", upsert, deletedAt)
+	}
+
+	_, err := tx.Exec(ctx, fmt.Sprintf("%s %s", upsert, t.onConflict(columns)))
+	return err
+}
+
+// onConflict returns conflict clause updating columns on primary key conflict
+func (t *table) onConflict(columns []string) string {
+	updates := []string{}
+	for _, column := range columns {
+		if utils.ExistInArray(t.primaryKey, column) {
+			continue
+		}
+
+		quoted := pgx.Identifier{column}.Sanitize()
+		updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", quoted, quoted))
+	}
+
+	if len(updates) == 0 {
+		return fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", quoteColumns(t.primaryKey))
+	}
+
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", quoteColumns(t.primaryKey), strings.Join(updates, ", "))
+}
+
+// deduplicate keeps the latest row per primary key; upserts can't touch a row twice. Columns
+// omitted from latest row are taken from earlier ones
+func (t *table) deduplicate() []types.RecordData {
+	index := make(map[string]int)
+	rows := []types.RecordData{}
+	for _, row := range t.rows {
+		keys := []string{}
+		for _, column := range t.primaryKey {
+			keys = append(keys, fmt.Sprint(row[column]))
+		}
+
+		key := strings.Join(keys, "\x00")
+		if i, found := index[key]; found {
+			latest := make(types.RecordData, len(row))
+			for column, value := range rows[i] {
+				if !cdcColumn(column) {
+					latest[column] = value
+				}
+			}
+			for column, value := range row {
+				latest[column] = value
+			}
+
+			rows[i] = latest
+			continue
+		}
+
+		index[key] = len(rows)
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// groupByColumns groups rows by columns they carry; columns of CDC metadata are always set
+func (t *table) groupByColumns(rows []types.RecordData) ([][]string, [][]types.RecordData) {
+	index := make(map[string]int)
+	columnSets := [][]string{}
+	groups := [][]types.RecordData{}
+	for _, row := range rows {
+		columns := []string{}
+		for _, column := range t.columns {
+			if _, found := row[column]; found || cdcColumn(column) {
+				columns = append(columns, column)
+			}
+		}
+
+		key := strings.Join(columns, "\x00")
+		i, found := index[key]
+		if !found {
+			i = len(groups)
+			index[key] = i
+			columnSets = append(columnSets, columns)
+			groups = append(groups, nil)
+		}
+
+		groups[i] = append(groups[i], row)
+	}
+
+	return columnSets, groups
+}
+
+// cdcColumn reports if column holds CDC metadata of a change rather than a source value
+func cdcColumn(column string) bool {
+	_, found := jdbc.CDCColumns[column]
+	return found || column == jdbc.CDCBefore
+}
+
+func copyRows(ctx context.Context, tx pgx.Tx, identifier pgx.Identifier, t *table, rows []types.RecordData) error {
+	_, err := tx.CopyFrom(ctx, identifier, t.columns, pgx.CopyFromSlice(len(rows), func(i int) ([]any, error) {
+		values := []any{}
+		for _, column := range t.columns {
+			value, err := reformat(t.properties[column], rows[i][column])
+			if err != nil {
+				return nil, fmt.Errorf("column[%s]: %s", column, err)
+			}
+
+			values = append(values, value)
+		}
+
+		return values, nil
+	}))
+
+	return err
+}
+
+func reformat(property *types.Property, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch datatype := property.DataType(); datatype {
+	case types.INT64, types.FLOAT64, types.BOOL, types.TIMESTAMP, types.DATE:
+		return typeutils.ReformatValue(datatype, value)
+	case types.DECIMAL:
+		decimal, err := typeutils.ReformatDecimal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		numeric := pgtype.Numeric{}
+		if err := numeric.Scan(decimal); err != nil {
+			return nil, err
+		}
+
+		return numeric, nil
+	case types.TIME:
+		text, err := typeutils.ReformatTime(value)
+		if err != nil {
+			return nil, err
+		}
+
+		// zone of timetz values is dropped
+		if i := strings.IndexAny(text[min(len(text), 8):], "+-"); i >= 0 {
+			text = text[:8+i]
+		}
+
+		clock := pgtype.Time{}
+		if err := clock.Scan(text); err != nil {
+			return nil, err
+		}
+
+		return clock, nil
+	case types.JSON:
+		return typeutils.ReformatJSON(value)
+	case types.ARRAY:
+		// encoded as json by pgx
+		return typeutils.ReformatValueOnProperty(property, value)
+	case types.OBJECT:
+		// encoded as json by pgx
+		return value, nil
+	default:
+		return typeutils.ReformatValue(types.STRING, value)
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/gear5sh/gear5"
+	adapter "github.com/gear5sh/gear5/adapters/postgres/internal"
+	"github.com/gear5sh/gear5/logger"
+)
+
+func main() {
+	adapter := &adapter.Postgres{}
+
+	cmd, err := gear5.RegisterAdapter(adapter)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	if err := cmd.Execute(); err != nil {
+		logger.Fatal(err)
+	}
+}
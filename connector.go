@@ -2,7 +2,6 @@ package gear5
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/gear5sh/gear5/logger"
 	protocol "github.com/gear5sh/gear5/protocol"
@@ -11,11 +10,16 @@ import (
 )
 
 var (
-	globalDriver  protocol.Driver
+	globalDriver  protocol.DriverV2
 	globalAdapter protocol.Adapter
 )
 
+// RegisterDriver runs a driver without context support through protocol.AdaptDriver
 func RegisterDriver(driver protocol.Driver) {
+	RegisterDriverV2(protocol.AdaptDriver(driver))
+}
+
+func RegisterDriverV2(driver protocol.DriverV2) {
 	defer safego.Recovery(true)
 
 	if globalAdapter != nil {
@@ -29,8 +33,6 @@ func RegisterDriver(driver protocol.Driver) {
 	if err != nil {
 		logger.Fatal(err)
 	}
-
-	os.Exit(0)
 }
 
 func RegisterAdapter(adapter protocol.Adapter) (*cobra.Command, error) {
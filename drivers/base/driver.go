@@ -22,25 +22,66 @@ func (d *Driver) BulkRead() bool {
 }
 
 func (d *Driver) UpdateState(stream protocol.Stream, data types.RecordData) error {
-	datatype, err := stream.Schema().GetType(stream.Cursor())
+	cursor, found := Cursor(stream, data)
+	if !found {
+		return nil
+	}
+
+	return AdvanceState(stream, cursor)
+}
+
+// Cursor returns cursor value of data; values of composite cursors are lists of field
+// values. Returns false if any cursor field is missing or null
+func Cursor(stream protocol.Stream, data types.RecordData) (any, bool) {
+	fields := stream.Self().CursorFields()
+	values := []any{}
+	for _, field := range fields {
+		value, found := data[field]
+		if !found || value == nil {
+			return nil, false
+		}
+
+		values = append(values, value)
+	}
+
+	if len(values) == 1 {
+		return values[0], true
+	}
+
+	return values, len(values) > 0
+}
+
+// CursorTypes returns types of cursor fields of stream
+func CursorTypes(stream protocol.Stream) ([]types.DataType, error) {
+	datatypes := []types.DataType{}
+	for _, field := range stream.Self().CursorFields() {
+		datatype, err := stream.Schema().GetType(field)
+		if err != nil {
+			return nil, err
+		}
+
+		datatypes = append(datatypes, datatype)
+	}
+
+	return datatypes, nil
+}
+
+// AdvanceState sets cursor as stream state unless state is already greater
+func AdvanceState(stream protocol.Stream, cursor any) error {
+	datatypes, err := CursorTypes(stream)
 	if err != nil {
 		return err
 	}
 
-	if cursorVal, found := data[stream.Cursor()]; found && cursorVal != nil {
-		// compare with current state
-		if stream.GetState() != nil {
-			state, err := typeutils.MaximumOnDataType(datatype, stream.GetState(), cursorVal)
-			if err != nil {
-				return err
-			}
-
-			stream.SetState(state)
-		} else {
-			// directly update
-			stream.SetState(cursorVal)
+	// compare with current state
+	if current := stream.GetState(); current != nil {
+		cursor, err = typeutils.MaximumCursor(datatypes, current, cursor)
+		if err != nil {
+			return err
 		}
 	}
 
+	stream.SetState(cursor)
+
 	return nil
 }
@@ -1,6 +1,7 @@
 package base
 
 import (
+	"context"
 	"time"
 
 	"github.com/gear5sh/gear5/logger"
@@ -12,14 +13,19 @@ type basestream interface {
 	Namespace() string
 }
 
-func RetryOnFailure(attempts int, sleep *time.Duration, f func() error) (err error) {
+// RetryOnFailure calls f until it succeeds; retries stop once ctx is done
+func RetryOnFailure(ctx context.Context, attempts int, sleep *time.Duration, f func() error) (err error) {
 	for i := 0; i < attempts; i++ {
 		if err = f(); err == nil {
 			return nil
 		}
 
 		logger.Infof("Retrying after %v...", sleep)
-		time.Sleep(*sleep)
+		select {
+		case <-time.After(*sleep):
+		case <-ctx.Done():
+			return err
+		}
 	}
 
 	return err
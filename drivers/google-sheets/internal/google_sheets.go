@@ -1,6 +1,7 @@
 package driver
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -67,7 +68,7 @@ func (gs *GoogleSheets) Discover() ([]*types.Stream, error) {
 	return streams, nil
 }
 
-func (gs *GoogleSheets) Read(stream protocol.Stream, channel chan<- types.Record) error {
+func (gs *GoogleSheets) Read(ctx context.Context, stream protocol.Stream, channel chan<- types.Record) error {
 	spreadsheetID := gs.config.SpreadsheetID
 
 	logger.Infof("Starting sync for spreadsheet [%s]", spreadsheetID)
@@ -91,6 +92,10 @@ func (gs *GoogleSheets) Read(stream protocol.Stream, channel chan<- types.Record
 	logger.Infof("Row count in sheet %s[id: %d]:%d", sheet.Properties.Title, sheet.Properties.ID, sheet.Properties.GridProperties.RowCount-1)
 
 	for rowCursor := int64(1); rowCursor < int64(len(sheet.Rows)); rowCursor++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		// make a batch of records
 		record := types.Record{Stream: stream.Name(), Namespace: stream.Namespace(), Data: make(map[string]interface{})}
 		for i, pointer := range sheet.Rows[rowCursor] {
@@ -88,7 +88,7 @@ func (s *Stream) PrimaryKey() []string {
 	return nil
 }
 
-func (s *Stream) properties() (map[string]*types.Property, error) {
+func (s *Stream) properties(ctx context.Context) (map[string]*types.Property, error) {
 	if s.entity == "" {
 		return nil, fmt.Errorf("entity found to be empty")
 	}
@@ -102,7 +102,7 @@ func (s *Stream) properties() (map[string]*types.Property, error) {
 		return s._properties, nil
 	}
 
-	req, err := http.NewRequest("GET", formatEndpoint(fmt.Sprintf("/properties/v2/%s/properties", s.entity)), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", formatEndpoint(fmt.Sprintf("/properties/v2/%s/properties", s.entity)), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -131,8 +131,8 @@ func (s *Stream) properties() (map[string]*types.Property, error) {
 	return s._properties, nil
 }
 
-func (s *Stream) propertiesList() ([]string, error) {
-	properties, err := s.properties()
+func (s *Stream) propertiesList(ctx context.Context) ([]string, error) {
+	properties, err := s.properties(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -211,7 +211,7 @@ func (s *Stream) nextPageToken(response any) (map[string]any, error) {
 	return nil, fmt.Errorf("failed to get next page token")
 }
 
-func (s *Stream) castRecordFieldsIfNeeded(record map[string]any) map[string]any {
+func (s *Stream) castRecordFieldsIfNeeded(ctx context.Context, record map[string]any) map[string]any {
 	if s.entity == "" {
 		return record
 	}
@@ -219,7 +219,7 @@ func (s *Stream) castRecordFieldsIfNeeded(record map[string]any) map[string]any
 		return record
 	}
 
-	properties, err := s.properties()
+	properties, err := s.properties(ctx)
 	if err != nil {
 		return record
 	}
@@ -243,9 +243,9 @@ func (s *Stream) castRecordFieldsIfNeeded(record map[string]any) map[string]any
 	return record
 }
 
-func (s *Stream) trasformSingleRecord(record map[string]any) map[string]any {
+func (s *Stream) trasformSingleRecord(ctx context.Context, record map[string]any) map[string]any {
 	// Preprocess a single record
-	record = s.castRecordFieldsIfNeeded(record)
+	record = s.castRecordFieldsIfNeeded(ctx, record)
 	if s.createdAtField != "" && s.updatedAtField != "" && record[s.updatedAtField] == nil {
 		record[s.updatedAtField] = record[s.createdAtField]
 	}
@@ -253,7 +253,7 @@ func (s *Stream) trasformSingleRecord(record map[string]any) map[string]any {
 	return record
 }
 
-func (s *Stream) transform(records []types.RecordData, err error) ([]types.RecordData, error) {
+func (s *Stream) transform(ctx context.Context, records []types.RecordData, err error) ([]types.RecordData, error) {
 	if err != nil {
 		return nil, err
 	}
@@ -261,7 +261,7 @@ func (s *Stream) transform(records []types.RecordData, err error) ([]types.Recor
 	// Preprocess record before emitting
 	transformed := []types.RecordData{}
 	for _, record := range records {
-		record = s.castRecordFieldsIfNeeded(record)
+		record = s.castRecordFieldsIfNeeded(ctx, record)
 		if s.createdAtField != "" && s.updatedAtField != "" && record[s.updatedAtField] == nil {
 			record[s.updatedAtField] = record[s.createdAtField]
 		}
@@ -338,7 +338,7 @@ func (s *Stream) flatAssociations(records []types.RecordData) []types.RecordData
 	return stream
 }
 
-func (s *Stream) handleRequest(request *utils.Request) (int, any, error) {
+func (s *Stream) handleRequest(ctx context.Context, request *utils.Request) (int, any, error) {
 	if request.URN == "" {
 		return 0, nil, fmt.Errorf("empty request url")
 	}
@@ -347,15 +347,21 @@ func (s *Stream) handleRequest(request *utils.Request) (int, any, error) {
 	if err != nil {
 		return 0, nil, err
 	}
+	req = req.WithContext(ctx)
 
 	statusCode := 0
 	var response any
 	retryAfter := time.Second
 
 	// only 3 attempts
-	err = base.RetryOnFailure(3, &retryAfter, func() error {
+	err = base.RetryOnFailure(ctx, 3, &retryAfter, func() error {
 		resp, err := s.client.Do(req)
 		if err != nil {
+			// deadline of ctx isn't a timeout of the server
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
 			if errors.Is(err, context.DeadlineExceeded) {
 				return utils.ErrServerTimeout
 			}
@@ -451,7 +457,7 @@ func (s *Stream) parseResponse(response interface{}) ([]types.RecordData, error)
 	return records, nil
 }
 
-func (s *Stream) readStreamRecords(nextPageToken map[string]any, f func() (path, method string)) ([]types.RecordData, any, error) {
+func (s *Stream) readStreamRecords(ctx context.Context, nextPageToken map[string]any, f func() (path, method string)) ([]types.RecordData, any, error) {
 	// properties = self._property_wrapper
 	//     for chunk in properties.split():
 	//         response = self.handle_request(
@@ -471,12 +477,13 @@ func (s *Stream) readStreamRecords(nextPageToken map[string]any, f func() (path,
 	}
 	request.QueryParams["limit"] = s.limit
 
-	_, response, err := s.handleRequest(request)
+	_, response, err := s.handleRequest(ctx, request)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	records, err := s.transform(s.parseResponse(response))
+	parsed, err := s.parseResponse(response)
+	records, err := s.transform(ctx, parsed, err)
 	if err != nil {
 		return nil, nil, err
 	}
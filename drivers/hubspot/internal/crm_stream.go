@@ -1,6 +1,7 @@
 package driver
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"time"
@@ -39,19 +40,19 @@ func (c *CRMSearchStream) path() (string, string) {
 	return fmt.Sprintf("/crm/v3/objects/%s", c.entity), http.MethodGet
 }
 
-func (c *CRMSearchStream) search() (int, any, error) {
+func (c *CRMSearchStream) search(ctx context.Context) (int, any, error) {
 	method, path := c.path()
 	request := &utils.Request{
 		URN:    formatEndpoint(path),
 		Method: method,
 	}
 
-	return c.handleRequest(request)
+	return c.handleRequest(ctx, request)
 }
 
-func (c *CRMSearchStream) processSearch(nextPageToken map[string]any) ([]map[string]any, any, error) {
+func (c *CRMSearchStream) processSearch(ctx context.Context, nextPageToken map[string]any) ([]map[string]any, any, error) {
 	streamRecords := []map[string]any{}
-	properties, err := c.propertiesList()
+	properties, err := c.propertiesList(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -73,12 +74,13 @@ func (c *CRMSearchStream) processSearch(nextPageToken map[string]any) ([]map[str
 		payload[key] = value
 	}
 
-	_, rawResponse, err := c.search()
+	_, rawResponse, err := c.search(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	records, err := c.transform(c.parseResponse(rawResponse))
+	parsed, err := c.parseResponse(rawResponse)
+	records, err := c.transform(ctx, parsed, err)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -90,7 +92,7 @@ func (c *CRMSearchStream) processSearch(nextPageToken map[string]any) ([]map[str
 	return streamRecords, rawResponse, nil
 }
 
-func (c *CRMSearchStream) readRecords(send chan<- types.Record) error {
+func (c *CRMSearchStream) readRecords(ctx context.Context, send chan<- types.Record) error {
 	paginationComplete := false
 	var nextPageToken map[string]any
 	latest_cursor := &time.Time{}
@@ -101,12 +103,12 @@ func (c *CRMSearchStream) readRecords(send chan<- types.Record) error {
 		var err error
 
 		if c.state_ != nil {
-			records, rawResponse, err = c.processSearch(nextPageToken)
+			records, rawResponse, err = c.processSearch(ctx, nextPageToken)
 			if err != nil {
 				return err
 			}
 		} else {
-			records, rawResponse, err = c.readStreamRecords(nextPageToken, c.path)
+			records, rawResponse, err = c.readStreamRecords(ctx, nextPageToken, c.path)
 			if err != nil {
 				return err
 			}
@@ -1,6 +1,7 @@
 package driver
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -24,7 +25,7 @@ type Hubspot struct {
 	config      *Config
 }
 
-func (h *Hubspot) Setup(config any, base *base.Driver) error {
+func (h *Hubspot) Setup(ctx context.Context, config any, base *base.Driver) error {
 	h.Driver = base
 
 	conf := &Config{}
@@ -36,7 +37,7 @@ func (h *Hubspot) Setup(config any, base *base.Driver) error {
 		return fmt.Errorf("failed to validate config: %s", err)
 	}
 
-	client, accessToken, err := newClient(conf)
+	client, accessToken, err := newClient(ctx, conf)
 	if err != nil {
 		return err
 	}
@@ -53,11 +54,11 @@ func (h *Hubspot) Spec() (schema.JSONSchema, error) {
 	return jsonschema.Reflect(Config{})
 }
 
-func (h *Hubspot) Check() error {
+func (h *Hubspot) Check(ctx context.Context) error {
 	return nil
 }
 
-func (h *Hubspot) Discover() ([]protocol.Stream, error) {
+func (h *Hubspot) Discover(ctx context.Context) ([]protocol.Stream, error) {
 	streams := []protocol.Stream{}
 
 	for _, hstream := range h.allStreams {
@@ -96,7 +97,7 @@ func (h *Hubspot) Type() string {
 // 	return state, nil
 // }
 
-func (h *Hubspot) Read(stream protocol.Stream, channel chan<- types.Record) error {
+func (h *Hubspot) Read(ctx context.Context, stream protocol.Stream, channel chan<- types.Record) error {
 	hstream, found := h.allStreams[stream.Name()]
 	if !found {
 		return fmt.Errorf("invalid stream passed: %s", stream.Name())
@@ -104,7 +105,7 @@ func (h *Hubspot) Read(stream protocol.Stream, channel chan<- types.Record) erro
 
 	// hstream.setup(stream.GetSyncMode(), h.Get(stream.Name(), stream.Namespace()))
 
-	err := hstream.readRecords(channel)
+	err := hstream.readRecords(ctx, channel)
 	if err != nil {
 		return fmt.Errorf("error occurred: %s", err)
 	}
@@ -112,8 +113,8 @@ func (h *Hubspot) Read(stream protocol.Stream, channel chan<- types.Record) erro
 	return nil
 }
 
-func (h *Hubspot) getGrantedScopes() ([]string, error) {
-	req, err := http.NewRequest("GET", formatEndpoint(fmt.Sprintf("oauth/v1/access-tokens/%s", h.accessToken)), nil)
+func (h *Hubspot) getGrantedScopes(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", formatEndpoint(fmt.Sprintf("oauth/v1/access-tokens/%s", h.accessToken)), nil)
 	if err != nil {
 		return nil, err
 	}
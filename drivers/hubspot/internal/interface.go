@@ -1,13 +1,15 @@
 package driver
 
 import (
+	"context"
+
 	"github.com/gear5sh/gear5/types"
 )
 
 type HubspotStream interface {
 	ScopeIsGranted(grantedScopes []string) bool
 	Name() string
-	readRecords(channel chan<- types.Record) error
+	readRecords(ctx context.Context, channel chan<- types.Record) error
 	Modes() []types.SyncMode
 	PrimaryKey() []string
 	path() (string, string)
@@ -47,7 +47,7 @@ func formatEndpoint(urn string) string {
 	return fmt.Sprintf("%s/%s", strings.TrimSuffix(BaseURL, "/"), strings.TrimPrefix(urn, "/"))
 }
 
-func newClient(config *Config) (*http.Client, string, error) {
+func newClient(ctx context.Context, config *Config) (*http.Client, string, error) {
 	var client *http.Client
 	var accessToken string
 	if ok, _ := utils.IsOfType(config.Credentials, "client_id"); ok {
@@ -67,7 +67,7 @@ func newClient(config *Config) (*http.Client, string, error) {
 		}
 
 		// Create a new token source using the refresh token
-		tokenSource := config.TokenSource(context.TODO(), &oauth2.Token{
+		tokenSource := config.TokenSource(ctx, &oauth2.Token{
 			RefreshToken: oauth.RefreshToken,
 		})
 
@@ -79,7 +79,7 @@ func newClient(config *Config) (*http.Client, string, error) {
 		accessToken = token.AccessToken
 
 		// Create a new OAuth2 client
-		client = oauth2.NewClient(context.TODO(), tokenSource)
+		client = oauth2.NewClient(ctx, tokenSource)
 	} else if ok, _ := utils.IsOfType(config.Credentials, "access_token"); ok {
 		logger.Info("Credentials found to be Private App")
 		privateApp := &PrivateApp{}
@@ -96,14 +96,14 @@ func newClient(config *Config) (*http.Client, string, error) {
 		}
 
 		// Create a new token source using the refresh token
-		tokenSource := config.TokenSource(context.TODO(), &oauth2.Token{
+		tokenSource := config.TokenSource(ctx, &oauth2.Token{
 			AccessToken: privateApp.AccessToken,
 		})
 
 		accessToken = privateApp.AccessToken
 
 		// Create a new OAuth2 client
-		client = oauth2.NewClient(context.TODO(), tokenSource)
+		client = oauth2.NewClient(ctx, tokenSource)
 	} else {
 		return nil, "", fmt.Errorf("invalid credentials format, expected formats are: %T and %T", Client{}, PrivateApp{})
 	}
@@ -7,5 +7,5 @@ import (
 
 func main() {
 	driver := &driver.Hubspot{}
-	gear5.RegisterDriver(driver)
+	gear5.RegisterDriverV2(driver)
 }
@@ -4,6 +4,8 @@ go 1.22
 
 require (
 	github.com/lib/pq v1.10.2
+	github.com/stretchr/testify v1.9.0
+	golang.org/x/sync v0.7.0
 	github.com/gear5sh/gear5 v0.0.0-20230630130252-054496f39abb
 )
 
@@ -1,16 +1,23 @@
 package driver
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gear5sh/gear5/drivers/base"
+	"github.com/gear5sh/gear5/logger"
 	"github.com/gear5sh/gear5/pkg/jdbc"
 	"github.com/gear5sh/gear5/pkg/waljs"
 	"github.com/gear5sh/gear5/protocol"
 	"github.com/gear5sh/gear5/safego"
 	"github.com/gear5sh/gear5/types"
+	"github.com/gear5sh/gear5/typeutils"
+	"github.com/gear5sh/gear5/utils"
+	"github.com/jackc/pglogrepl"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 func (p *Postgres) prepareWALJSConfig(streams ...protocol.Stream) (*waljs.Config, error) {
@@ -21,14 +28,24 @@ func (p *Postgres) prepareWALJSConfig(streams ...protocol.Stream) (*waljs.Config
 	config := &waljs.Config{
 		Connection:          *p.config.Connection,
 		ReplicationSlotName: p.cdcConfig.ReplicationSlot,
+		Plugin:              p.cdcConfig.Plugin,
+		Publication:         p.cdcConfig.Publication,
 		InitialWaitTime:     time.Duration(p.cdcConfig.InitialWaitTime) * time.Second,
+		Done:                p.done,
 		State:               p.cdcState,
 		FullSyncTables:      types.NewSet[protocol.Stream](),
 		Tables:              types.NewSet[protocol.Stream](),
+		Partitions:          p.partitions,
+	}
+
+	if p.cdcConfig.IncrementalSnapshot {
+		config.SignalTable = p.cdcConfig.SignalTable
+		config.ChunkSize = p.cdcConfig.SnapshotChunkSize
 	}
 
 	for _, stream := range streams {
-		if stream.GetState() == nil {
+		// interrupted incremental snapshots are resumed
+		if stream.GetState() == nil || stream.GetStateKey(waljs.SnapshotStateKey) != nil {
 			config.FullSyncTables.Insert(stream)
 		}
 
@@ -38,6 +55,46 @@ func (p *Postgres) prepareWALJSConfig(streams ...protocol.Stream) (*waljs.Config
 	return config, nil
 }
 
+// Follow keeps streaming changes in GroupRead until ctx is done
+func (p *Postgres) Follow(ctx context.Context) {
+	p.done = ctx.Done()
+}
+
+// CheckRewind fails unless replication slot can stream changes from lsn again; slots stream
+// from their confirmed flush LSN at the earliest, changes before it are only recovered by
+// loading streams in full
+func (p *Postgres) CheckRewind(ctx context.Context, lsn string) error {
+	if err := p.Check(ctx); err != nil {
+		return err
+	}
+
+	if !p.Driver.GroupRead {
+		return fmt.Errorf("%s is not running in CDC mode", p.Type())
+	}
+
+	position, err := pglogrepl.ParseLSN(lsn)
+	if err != nil {
+		return err
+	}
+
+	db, err := p.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	slot := waljs.ReplicationSlot{}
+	if err := db.GetContext(ctx, &slot, fmt.Sprintf(waljs.ReplicationSlotTempl, p.cdcConfig.ReplicationSlot)); err != nil {
+		return fmt.Errorf("failed to get replication slot %s: %s", p.cdcConfig.ReplicationSlot, err)
+	}
+
+	if position < slot.LSN {
+		return fmt.Errorf("replication slot %s confirmed changes up to %s and can't stream earlier ones; use resync-cdc to load CDC streams in full", p.cdcConfig.ReplicationSlot, slot.LSN)
+	}
+
+	return nil
+}
+
 func (p *Postgres) StateType() types.StateType {
 	return types.MixedType
 }
@@ -46,7 +103,7 @@ func (p *Postgres) StateType() types.StateType {
 // 	return p.cdcState
 // }
 
-func (p *Postgres) SetupGlobalState(state *types.State) error {
+func (p *Postgres) SetupGlobalState(ctx context.Context, state *types.State) error {
 	state.Type = p.StateType()
 	// Setup raw state
 	p.cdcState = types.NewGlobalState(&waljs.WALState{})
@@ -55,18 +112,125 @@ func (p *Postgres) SetupGlobalState(state *types.State) error {
 }
 
 // Write Ahead Log Sync
-func (p *Postgres) GroupRead(channel chan<- types.Record, streams ...protocol.Stream) error {
+func (p *Postgres) GroupRead(ctx context.Context, channel chan<- types.Record, selected ...protocol.Stream) error {
+	// slot must exist before streams are read so that no change made meanwhile is missed
+	if p.cdcConfig.ManageSlot {
+		if err := prepareReplication(ctx, p.client, &p.cdcConfig); err != nil {
+			return err
+		}
+	}
+
+	// relations that can't be replicated e.g. views are read in full before streaming changes
+	streams := []protocol.Stream{}
+	for _, stream := range selected {
+		if stream.GetSyncMode() == types.CDC {
+			// streams with cursors of incremental syncs e.g. of a migrated state catch up
+			// from their cursors before the first changes are streamed instead of a full load
+			if p.cdcState.State.IsEmpty() && stream.Cursor() != "" && stream.GetState() != nil {
+				logger.Infof("Catching up %s from cursor %v", stream.ID(), stream.InitialState())
+				if err := p.incrementalSync(ctx, stream, channel); err != nil {
+					return err
+				}
+			}
+
+			streams = append(streams, stream)
+			continue
+		}
+
+		logger.Infof("Reading stream %s", stream.ID())
+		if err := p.Read(ctx, stream, channel); err != nil {
+			return err
+		}
+	}
+
+	if len(streams) == 0 || ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	// streams are only known once read so that check can't validate replica identity
+	if err := checkReplicaIdentity(ctx, p.client, p.partitions, streams...); err != nil {
+		return err
+	}
+
 	config, err := p.prepareWALJSConfig(streams...)
 	if err != nil {
 		return err
 	}
 
-	socket, err := waljs.NewConnection(p.client, config)
+	if p.cdcConfig.ManageSlot && p.cdcConfig.Plugin == waljs.PgOutput {
+		if err := syncPublication(ctx, p.client, p.cdcConfig.Publication, p.signalTable(), streams...); err != nil {
+			return fmt.Errorf("failed to sync publication tables: %s", err)
+		}
+	}
+
+	socket, err := waljs.NewConnection(ctx, p.client, config)
 	if err != nil {
 		return err
 	}
 
+	// cursor maxima of the transaction being received; applied on commit
+	pending := cursors{}
 	return socket.OnMessage(func(message waljs.WalJSChange) (bool, error) {
+		if message.Kind == "commit" {
+			for stream, value := range pending {
+				if err := base.AdvanceState(stream, value); err != nil {
+					return true, err
+				}
+			}
+
+			pending = cursors{}
+			return false, nil
+		}
+
+		// checkpoint is reached once every change before it has been emitted
+		if message.Checkpoint != nil {
+			if !safego.Insert(channel, types.Record{Checkpoint: message.Checkpoint}) {
+				return true, nil
+			}
+
+			return false, nil
+		}
+
+		if message.Kind == "truncate" {
+			action := &types.ActionRow{
+				Type:      types.TRUNCATE,
+				Namespace: message.Stream.Namespace(),
+				Stream:    message.Stream.Name(),
+			}
+
+			return !safego.Insert(channel, types.Record{Action: action}), nil
+		}
+
+		matched, err := matches(message)
+		if err != nil {
+			return true, err
+		}
+
+		if !matched {
+			// rows updated out of filter are deleted from destination
+			left, err := leftFilter(message)
+			if err != nil || !left {
+				return err != nil, err
+			}
+
+			message.Kind = "delete"
+		}
+
+		// destination is altered before first record with the new schema
+		if altered := alterSchema(message.Stream, message.ColumnTypes); len(altered) > 0 {
+			logger.Infof("Schema of %s changed; altering %d columns", message.Stream.ID(), len(altered))
+			action := &types.ActionRow{
+				Type:      types.ALTER,
+				Namespace: message.Stream.Namespace(),
+				Stream:    message.Stream.Name(),
+				Columns:   altered,
+			}
+
+			if !safego.Insert(channel, types.Record{Action: action}) {
+				return true, nil
+			}
+		}
+
 		if message.Kind == "delete" {
 			message.Data[jdbc.CDCDeletedAt] = message.Timestamp
 		}
@@ -76,6 +240,13 @@ func (p *Postgres) GroupRead(channel chan<- types.Record, streams ...protocol.St
 		if message.LSN != nil {
 			message.Data[jdbc.CDCLSN] = message.LSN
 		}
+		message.Data[jdbc.CDCOp] = message.Kind
+		if message.Xid != 0 {
+			message.Data[jdbc.CDCXid] = message.Xid
+		}
+		if p.cdcConfig.IncludeBefore && message.Before != nil {
+			message.Data[jdbc.CDCBefore] = message.Before
+		}
 
 		// insert record
 		if !safego.Insert(channel, base.ReformatRecord(message.Stream, message.Data)) {
@@ -83,7 +254,12 @@ func (p *Postgres) GroupRead(channel chan<- types.Record, streams ...protocol.St
 			return true, nil
 		}
 
-		err = p.UpdateState(message.Stream, message.Data)
+		// snapshot rows are read outside of replicated transactions
+		if message.Xid == 0 {
+			err = p.UpdateState(message.Stream, message.Data)
+		} else {
+			err = pending.add(message.Stream, message.Data)
+		}
 		if err != nil {
 			return true, err
 		}
@@ -92,9 +268,76 @@ func (p *Postgres) GroupRead(channel chan<- types.Record, streams ...protocol.St
 	})
 }
 
-func doesReplicationSlotExists(conn *sqlx.DB, slotName string) (bool, error) {
+// matches evaluates filter of stream on a change; deletes missing filter columns are skipped
+// as their rows can't be proven to match
+func matches(message waljs.WalJSChange) (bool, error) {
+	filter, err := message.Stream.Self().RowFilter()
+	if err != nil || filter == nil {
+		return err == nil, err
+	}
+
+	if message.Kind == "delete" {
+		for _, column := range filter.Columns() {
+			if _, found := message.Data[column]; !found {
+				return false, nil
+			}
+		}
+	}
+
+	return typeutils.Matches(filter, message.Stream.Schema(), message.Data)
+}
+
+// leftFilter tells if an update changed a row matching filter so that it no longer matches;
+// rows without previous values of filter columns are skipped as they can't be proven to have
+// matched. Filtered streams require REPLICA IDENTITY FULL so that previous values are sent
+func leftFilter(message waljs.WalJSChange) (bool, error) {
+	if message.Kind != "update" {
+		return false, nil
+	}
+
+	filter, err := message.Stream.Self().RowFilter()
+	if err != nil || filter == nil {
+		return false, err
+	}
+
+	for _, column := range filter.Columns() {
+		if _, found := message.Before[column]; !found {
+			return false, nil
+		}
+	}
+
+	return typeutils.Matches(filter, message.Stream.Schema(), message.Before)
+}
+
+// cursors holds cursor maxima per stream
+type cursors map[protocol.Stream]any
+
+func (c cursors) add(stream protocol.Stream, data types.RecordData) error {
+	value, found := base.Cursor(stream, data)
+	if !found {
+		return nil
+	}
+
+	if current, found := c[stream]; found {
+		datatypes, err := base.CursorTypes(stream)
+		if err != nil {
+			return err
+		}
+
+		value, err = typeutils.MaximumCursor(datatypes, current, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	c[stream] = value
+
+	return nil
+}
+
+func doesReplicationSlotExists(ctx context.Context, conn *sqlx.DB, slotName, plugin string) (bool, error) {
 	var exists bool
-	err := conn.QueryRow(
+	err := conn.QueryRowContext(ctx,
 		"SELECT EXISTS(Select 1 from pg_replication_slots where slot_name = $1)",
 		slotName,
 	).Scan(&exists)
@@ -102,18 +345,22 @@ func doesReplicationSlotExists(conn *sqlx.DB, slotName string) (bool, error) {
 		return false, err
 	}
 
-	return exists, validateReplicationSlot(conn, slotName)
+	if !exists {
+		return false, nil
+	}
+
+	return exists, validateReplicationSlot(ctx, conn, slotName, plugin)
 }
 
-func validateReplicationSlot(conn *sqlx.DB, slotName string) error {
+func validateReplicationSlot(ctx context.Context, conn *sqlx.DB, slotName, plugin string) error {
 	slot := waljs.ReplicationSlot{}
-	err := conn.Get(&slot, fmt.Sprintf(waljs.ReplicationSlotTempl, slotName))
+	err := conn.GetContext(ctx, &slot, fmt.Sprintf(waljs.ReplicationSlotTempl, slotName))
 	if err != nil {
 		return err
 	}
 
-	if slot.Plugin != "wal2json" {
-		return fmt.Errorf("Plugin not supported[%s]: driver only supports wal2json", slot.Plugin)
+	if slot.Plugin != plugin {
+		return fmt.Errorf("Plugin mismatch[%s]: replication slot is configured with %s", slot.Plugin, plugin)
 	}
 
 	if slot.SlotType != "logical" {
@@ -122,3 +369,276 @@ func validateReplicationSlot(conn *sqlx.DB, slotName string) error {
 
 	return nil
 }
+
+// alterSchema updates stream schema with added columns and changed types; returns altered columns
+func alterSchema(stream protocol.Stream, columnTypes map[string]string) map[string]*types.Property {
+	altered := make(map[string]*types.Property)
+	for column, pgType := range columnTypes {
+		datatype, found := dataTypeOf(pgType)
+		if !found {
+			datatype = types.UNKNOWN
+		}
+
+		nullable := true
+		if property, found := stream.Schema().Properties[column]; found {
+			if property.DataType() == datatype || datatype == types.UNKNOWN {
+				continue
+			}
+
+			nullable = property.Nullable()
+		}
+
+		property := &types.Property{Type: []types.DataType{datatype}}
+		if nullable {
+			property.Type = append(property.Type, types.NULL)
+		}
+
+		altered[column] = property
+	}
+
+	// schema is swapped as catalog is logged concurrently
+	if len(altered) > 0 {
+		stream.Self().AlterSchema(altered)
+	}
+
+	return altered
+}
+
+func createReplicationSlot(ctx context.Context, conn *sqlx.DB, slotName, plugin string) error {
+	logger.Infof("Creating replication slot %s with plugin %s", slotName, plugin)
+
+	_, err := conn.ExecContext(ctx, "SELECT pg_create_logical_replication_slot($1, $2)", slotName, plugin)
+	return err
+}
+
+func dropReplicationSlot(ctx context.Context, conn *sqlx.DB, slotName string) error {
+	logger.Infof("Dropping replication slot %s", slotName)
+
+	_, err := conn.ExecContext(ctx, "SELECT pg_drop_replication_slot(slot_name) FROM pg_replication_slots WHERE slot_name = $1", slotName)
+	return err
+}
+
+// checkReplicaIdentity fails on filtered streams whose tables don't send previous values of
+// updated and deleted rows; without them changes of rows outside filter can't be told apart
+func checkReplicaIdentity(ctx context.Context, conn *sqlx.DB, partitions map[string]string, streams ...protocol.Stream) error {
+	for _, stream := range streams {
+		if stream.Self().Filter == "" {
+			continue
+		}
+
+		// changes of partitioned tables are sent by their partitions
+		tables := []string{quoteTable(stream.Namespace(), stream.Name())}
+		for partition, root := range partitions {
+			if root == stream.ID() {
+				schema, name, _ := strings.Cut(partition, ".")
+				tables = append(tables, quoteTable(schema, name))
+			}
+		}
+
+		for _, table := range tables {
+			var identity string
+			err := conn.QueryRowContext(ctx, "SELECT relreplident::text FROM pg_class WHERE oid = to_regclass($1)", table).Scan(&identity)
+			if err != nil {
+				return fmt.Errorf("failed to check replica identity of %s: %s", table, err)
+			}
+
+			if identity != "f" {
+				return fmt.Errorf("filtered stream %s requires REPLICA IDENTITY FULL on %s", stream.ID(), table)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkReplication reports replication resources missing from database; resources are
+// required to exist unless managed by the driver
+func checkReplication(ctx context.Context, conn *sqlx.DB, cdc *CDC) error {
+	missing := func(resource string) error {
+		if !cdc.ManageSlot {
+			return fmt.Errorf("%s does not exist!", resource)
+		}
+
+		logger.Infof("%s does not exist; it is created once a read starts", resource)
+		return nil
+	}
+
+	exists, err := doesReplicationSlotExists(ctx, conn, cdc.ReplicationSlot, cdc.Plugin)
+	if err != nil {
+		return fmt.Errorf("failed to check replication slot: %s", err)
+	}
+	if !exists {
+		if err := missing(fmt.Sprintf("replication slot %s", cdc.ReplicationSlot)); err != nil {
+			return err
+		}
+	}
+
+	if cdc.Plugin == waljs.PgOutput {
+		exists, err := publicationExists(ctx, conn, cdc.Publication)
+		if err != nil {
+			return fmt.Errorf("failed to check publication: %s", err)
+		}
+		if !exists {
+			if err := missing(fmt.Sprintf("publication %s", cdc.Publication)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cdc.IncrementalSnapshot {
+		exists, err := signalTableExists(ctx, conn, cdc.SignalTable)
+		if err != nil {
+			return fmt.Errorf("failed to check signal table: %s", err)
+		}
+		if !exists {
+			if err := missing(fmt.Sprintf("signal table %s", cdc.SignalTable)); err != nil {
+				return err
+			}
+		}
+
+		// watermarks are only streamed once signal table is published; managed
+		// publications are synced with it on read
+		if cdc.Plugin == waljs.PgOutput && !cdc.ManageSlot {
+			published, err := tablePublished(ctx, conn, cdc.Publication, cdc.SignalTable)
+			if err != nil {
+				return fmt.Errorf("failed to check publication of signal table: %s", err)
+			}
+			if !published {
+				return fmt.Errorf("signal table %s is not in publication %s", cdc.SignalTable, cdc.Publication)
+			}
+		}
+	}
+
+	return nil
+}
+
+// tablePublished reports if table i.e. schema.name is published by publication
+func tablePublished(ctx context.Context, conn *sqlx.DB, publication, table string) (bool, error) {
+	schema, name, _ := strings.Cut(table, ".")
+	var published bool
+	err := conn.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM pg_publication_tables WHERE pubname = $1 AND schemaname = $2 AND tablename = $3)", publication, schema, name).Scan(&published)
+	return published, err
+}
+
+// prepareReplication creates signal table, publication and replication slot missing from database
+func prepareReplication(ctx context.Context, conn *sqlx.DB, cdc *CDC) error {
+	if cdc.IncrementalSnapshot {
+		if err := ensureSignalTable(ctx, conn, cdc.SignalTable); err != nil {
+			return fmt.Errorf("failed to create signal table: %s", err)
+		}
+	}
+
+	// publication must exist before slot starts decoding
+	if cdc.Plugin == waljs.PgOutput {
+		if err := ensurePublication(ctx, conn, cdc.Publication); err != nil {
+			return fmt.Errorf("failed to create publication: %s", err)
+		}
+	}
+
+	exists, err := doesReplicationSlotExists(ctx, conn, cdc.ReplicationSlot, cdc.Plugin)
+	if err != nil {
+		return fmt.Errorf("failed to check replication slot: %s", err)
+	}
+
+	if !exists {
+		if err := createReplicationSlot(ctx, conn, cdc.ReplicationSlot, cdc.Plugin); err != nil {
+			return fmt.Errorf("failed to create replication slot: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func publicationExists(ctx context.Context, conn *sqlx.DB, publication string) (bool, error) {
+	var exists bool
+	err := conn.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM pg_publication WHERE pubname = $1)", publication).Scan(&exists)
+	return exists, err
+}
+
+func ensurePublication(ctx context.Context, conn *sqlx.DB, publication string) error {
+	exists, err := publicationExists(ctx, conn, publication)
+	if err != nil || exists {
+		return err
+	}
+
+	logger.Infof("Creating publication %s", publication)
+
+	_, err = conn.ExecContext(ctx, fmt.Sprintf("CREATE PUBLICATION %s", pq.QuoteIdentifier(publication)))
+	return err
+}
+
+// syncPublication adds selected streams and signal table missing from publication and drops the rest
+func syncPublication(ctx context.Context, conn *sqlx.DB, publication, signal string, streams ...protocol.Stream) error {
+	var tables []Table
+	if err := conn.SelectContext(ctx, &tables, getPublicationTablesTmpl, publication); err != nil {
+		return err
+	}
+
+	published := make(map[string]bool)
+	dropped := []string{}
+	for _, table := range tables {
+		id := utils.StreamIdentifier(table.Name, table.Schema)
+		published[id] = true
+
+		_, selected := utils.ArrayContains(streams, func(stream protocol.Stream) bool {
+			return stream.ID() == id
+		})
+		if !selected && id != signal {
+			dropped = append(dropped, quoteTable(table.Schema, table.Name))
+		}
+	}
+
+	added := []string{}
+	for _, stream := range streams {
+		if !published[stream.ID()] {
+			added = append(added, quoteTable(stream.Namespace(), stream.Name()))
+		}
+	}
+
+	if signal != "" && !published[signal] {
+		schema, name, _ := strings.Cut(signal, ".")
+		added = append(added, quoteTable(schema, name))
+	}
+
+	if len(added) > 0 {
+		logger.Infof("Adding tables %s to publication %s", strings.Join(added, ", "), publication)
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("ALTER PUBLICATION %s ADD TABLE %s", pq.QuoteIdentifier(publication), strings.Join(added, ", "))); err != nil {
+			return err
+		}
+	}
+
+	if len(dropped) > 0 {
+		logger.Infof("Dropping tables %s from publication %s", strings.Join(dropped, ", "), publication)
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("ALTER PUBLICATION %s DROP TABLE %s", pq.QuoteIdentifier(publication), strings.Join(dropped, ", "))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// signalTable returns signal table of incremental snapshots; empty if disabled
+func (p *Postgres) signalTable() string {
+	if !p.cdcConfig.IncrementalSnapshot {
+		return ""
+	}
+
+	return p.cdcConfig.SignalTable
+}
+
+func signalTableExists(ctx context.Context, conn *sqlx.DB, signal string) (bool, error) {
+	schema, name, _ := strings.Cut(signal, ".")
+	var exists bool
+	err := conn.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", quoteTable(schema, name)).Scan(&exists)
+	return exists, err
+}
+
+func ensureSignalTable(ctx context.Context, conn *sqlx.DB, signal string) error {
+	schema, name, _ := strings.Cut(signal, ".")
+	_, err := conn.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id TEXT PRIMARY KEY, type TEXT NOT NULL, data TEXT)", quoteTable(schema, name)))
+	return err
+}
+
+func quoteTable(schema, name string) string {
+	return fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(name))
+}
@@ -0,0 +1,79 @@
+package driver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gear5sh/gear5/pkg/waljs"
+	"github.com/gear5sh/gear5/types"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run with -race; columns are altered by the replication goroutine while the consumer
+// encodes catalog on every ALTER alike the read command
+func TestAlterSchemaWhileLoggingCatalog(t *testing.T) {
+	stream := types.NewStream("users", "public")
+	stream.UpsertField("id", types.INT64, false)
+	configured := stream.Wrap(100)
+	catalog := &types.Catalog{Streams: []*types.ConfiguredStream{configured}}
+
+	channel := make(chan types.Record, 8)
+	go func() {
+		defer close(channel)
+
+		for i := 0; i < 200; i++ {
+			altered := alterSchema(configured, map[string]string{"id": "bigint", fmt.Sprintf("column_%d", i): "text"})
+			channel <- types.Record{Action: &types.ActionRow{Type: types.ALTER, Columns: altered}}
+		}
+	}()
+
+	alters := 0
+	for record := range channel {
+		require.Equal(t, types.ALTER, record.Action.Type)
+		assert.Len(t, record.Action.Columns, 1)
+
+		_, err := json.Marshal(types.Message{Type: types.CataLogMessage, Catalog: catalog})
+		require.NoError(t, err)
+		alters++
+	}
+
+	assert.Equal(t, 200, alters)
+	assert.Len(t, configured.Schema().Properties, 201)
+	assert.True(t, configured.Schema().Properties["column_7"].Nullable())
+	assert.False(t, configured.Schema().Properties["id"].Nullable())
+}
+
+func TestLeftFilter(t *testing.T) {
+	stream := types.NewStream("users", "public")
+	stream.UpsertField("tenant_id", types.INT64, false)
+	configured := stream.Wrap(100)
+	configured.Filter = "tenant_id = 42"
+
+	tests := []struct {
+		name   string
+		kind   string
+		before map[string]any
+		left   bool
+	}{
+		{"updated out of filter", "update", map[string]any{"tenant_id": 42}, true},
+		{"never matched", "update", map[string]any{"tenant_id": 7}, false},
+		{"previous values unknown", "update", nil, false},
+		{"insert", "insert", nil, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message := waljs.WalJSChange{Stream: configured, Kind: test.kind, Data: map[string]any{"tenant_id": 7}, Before: test.before}
+
+			matched, err := matches(message)
+			require.NoError(t, err)
+			assert.False(t, matched)
+
+			left, err := leftFilter(message)
+			require.NoError(t, err)
+			assert.Equal(t, test.left, left)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gear5sh/gear5/drivers/base"
+	"github.com/gear5sh/gear5/logger"
+	"github.com/gear5sh/gear5/pkg/jdbc"
+	"github.com/gear5sh/gear5/protocol"
+	"github.com/gear5sh/gear5/safego"
+	"github.com/gear5sh/gear5/types"
+	"github.com/gear5sh/gear5/utils"
+	"github.com/lib/pq"
+	"golang.org/x/sync/errgroup"
+)
+
+// state key for progress of a full load
+const fullLoadStateKey = "full_load"
+
+// fullLoadState is progress of a full load in primary key order i.e. the key of the last
+// row loaded. Chunked loads record no progress as they can't be resumed consistently
+type fullLoadState struct {
+	After []string `json:"after,omitempty"` // primary key of the last row loaded
+}
+
+type chunk struct {
+	start int64
+	end   int64 // first page after chunk; zero for the last chunk
+}
+
+// Full Refresh Sync reading table in ctid page ranges concurrently; all workers share a
+// single exported snapshot. Chunks read on a later snapshot would miss rows moved into
+// chunks already read, hence an interrupted load is restarted from scratch
+func (p *Postgres) chunkedSync(ctx context.Context, stream protocol.Stream, channel chan<- types.Record) error {
+	var pages int64
+	err := p.client.QueryRowContext(ctx, getRelationPagesTmpl, stream.Namespace(), stream.Name()).Scan(&pages)
+	if err != nil {
+		return fmt.Errorf("failed to get relation size: %s", err)
+	}
+
+	// views and empty tables have no pages to split
+	if pages == 0 {
+		return freshSync(ctx, p.client, stream, p.stored[stream.ID()], channel)
+	}
+
+	// destination isn't truncated by read as stream has state of the interrupted load
+	if stream.GetStateKey(fullLoadStateKey) != nil {
+		logger.Warnf("Full load of %s was interrupted; restarting as chunks can't be resumed", stream.ID())
+		if err := restartFullLoad(stream, channel); err != nil {
+			return err
+		}
+	}
+
+	chunks := []chunk{}
+	for start := int64(0); start < pages; start += p.config.ChunkPages {
+		end := start + p.config.ChunkPages
+		if end >= pages {
+			// rows added past the measured size are still read
+			end = 0
+		}
+
+		chunks = append(chunks, chunk{start: start, end: end})
+	}
+
+	// exporting transaction must stay open until every worker has imported the snapshot
+	conn, err := p.client.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var snapshot string
+	if err := tx.QueryRowContext(ctx, "SELECT pg_export_snapshot()").Scan(&snapshot); err != nil {
+		return fmt.Errorf("failed to export snapshot: %s", err)
+	}
+
+	logger.Infof("Reading %s in %d chunks with %d workers", stream.ID(), len(chunks), p.config.ReaderWorkers)
+
+	queue := make(chan chunk, len(chunks))
+	for _, c := range chunks {
+		queue <- c
+	}
+	close(queue)
+
+	// workers stop once one of them fails
+	group, groupCtx := errgroup.WithContext(ctx)
+	for i := 0; i < p.config.ReaderWorkers; i++ {
+		group.Go(func() error {
+			for c := range queue {
+				if err := p.readChunk(groupCtx, snapshot, stream, c, channel); err != nil {
+					return fmt.Errorf("failed to read chunk at page %d: %s", c.start, err)
+				}
+			}
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return err
+	}
+
+	// next full refresh starts over
+	safego.Insert(channel, types.Record{Checkpoint: func() {
+		stream.SetStateKey(fullLoadStateKey, nil)
+	}})
+
+	return nil
+}
+
+// restartFullLoad truncates destination rows of an interrupted full load that can't be resumed
+func restartFullLoad(stream protocol.Stream, channel chan<- types.Record) error {
+	action := &types.ActionRow{
+		Type:      types.TRUNCATE,
+		Namespace: stream.Namespace(),
+		Stream:    stream.Name(),
+	}
+
+	if !safego.Insert(channel, types.Record{Action: action}) {
+		return fmt.Errorf("channel closed before full load of %s restarted", stream.ID())
+	}
+
+	return nil
+}
+
+func (p *Postgres) readChunk(ctx context.Context, snapshot string, stream protocol.Stream, c chunk, channel chan<- types.Record) error {
+	tx, err := p.client.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET TRANSACTION SNAPSHOT %s", pq.QuoteLiteral(snapshot))); err != nil {
+		return fmt.Errorf("failed to import snapshot: %s", err)
+	}
+
+	end := ""
+	if c.end > 0 {
+		end = fmt.Sprintf("(%d,0)", c.end)
+	}
+
+	query, args, err := jdbc.PostgresChunk(stream, fmt.Sprintf("(%d,0)", c.start), end)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		record := make(types.RecordData)
+		if err := utils.MapScan(rows, record); err != nil {
+			return fmt.Errorf("failed to mapScan record data: %s", err)
+		}
+
+		if !safego.Insert(channel, base.ReformatRecord(stream, record)) {
+			// channel was closed
+			return nil
+		}
+	}
+
+	return rows.Err()
+}
@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/gear5sh/gear5/pkg/waljs"
 	"github.com/gear5sh/gear5/utils"
 	"github.com/lib/pq"
 )
@@ -65,6 +66,22 @@ type Config struct {
 	// oneOf=["Standard","CDC"]
 	// )
 	UpdateMethod interface{} `json:"update_method"`
+	// Number of workers reading a table concurrently in full refresh; tables are read
+	// in chunks of pages sharing a single snapshot when set above 1 and interrupted
+	// loads restart from scratch
+	//
+	// @jsonschema(
+	// minimum=1,
+	// default=1
+	// )
+	ReaderWorkers int `json:"reader_workers"`
+	// Number of table pages read as one chunk in concurrent full refresh
+	//
+	// @jsonschema(
+	// minimum=1,
+	// default=10000
+	// )
+	ChunkPages int64 `json:"chunk_pages"`
 }
 
 // Standard Sync
@@ -86,6 +103,82 @@ type CDC struct {
 	// default=0
 	// )
 	InitialWaitTime int `json:"intial_wait_time"`
+	// Logical decoding plugin of the replication slot
+	//
+	// @jsonschema(
+	// enum=["wal2json","pgoutput"],
+	// default="wal2json"
+	// )
+	Plugin string `json:"plugin"`
+	// Publication streamed by the slot; required with pgoutput
+	Publication string `json:"publication"`
+	// Create replication slot and publication if missing once a read starts; publication
+	// tables follow the selected streams
+	//
+	// @jsonschema(
+	// default=false
+	// )
+	ManageSlot bool `json:"manage_slot"`
+	// Include previous row of updates and deletes as _cdc_before; full rows require
+	// REPLICA IDENTITY FULL, otherwise only keys are available
+	//
+	// @jsonschema(
+	// default=false
+	// )
+	IncludeBefore bool `json:"include_before"`
+	// Snapshot new tables in chunks while changes are streamed instead of before
+	// replication starts; requires a primary key and a signal table
+	//
+	// @jsonschema(
+	// default=false
+	// )
+	IncrementalSnapshot bool `json:"incremental_snapshot"`
+	// Table written with watermarks of incremental snapshot chunks; created when slot is
+	// managed, otherwise it must have columns id text primary key, type text and data text
+	//
+	// @jsonschema(
+	// default="public.gear5_signal"
+	// )
+	SignalTable string `json:"signal_table"`
+	// Number of rows read per chunk of an incremental snapshot
+	//
+	// @jsonschema(
+	// minimum=1,
+	// default=10000
+	// )
+	SnapshotChunkSize int `json:"snapshot_chunk_size"`
+}
+
+func (c *CDC) Validate() error {
+	if c.Plugin == "" {
+		c.Plugin = waljs.Wal2JSON
+	}
+
+	switch c.Plugin {
+	case waljs.Wal2JSON:
+	case waljs.PgOutput:
+		if c.Publication == "" {
+			return fmt.Errorf("publication is required with plugin %s", c.Plugin)
+		}
+	default:
+		return fmt.Errorf("unsupported plugin %s; supported are %s and %s", c.Plugin, waljs.Wal2JSON, waljs.PgOutput)
+	}
+
+	if c.IncrementalSnapshot {
+		if c.SignalTable == "" {
+			c.SignalTable = "public.gear5_signal"
+		}
+
+		if !strings.Contains(c.SignalTable, ".") {
+			return fmt.Errorf("signal table must be qualified by schema: %s", c.SignalTable)
+		}
+
+		if c.SnapshotChunkSize <= 0 {
+			c.SnapshotChunkSize = 10000
+		}
+	}
+
+	return nil
 }
 
 func (c *Config) Validate() error {
@@ -99,6 +192,14 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("ssl config not set")
 	}
 
+	if c.ReaderWorkers <= 0 {
+		c.ReaderWorkers = 1
+	}
+
+	if c.ChunkPages <= 0 {
+		c.ChunkPages = 10000
+	}
+
 	// construct the connection string
 	connStr := fmt.Sprintf("postgres://%s:%s@%s:%d/%s", c.Username, c.Password, c.Host, c.Port, c.Database)
 	parsed, err := url.Parse(connStr)
@@ -146,10 +247,51 @@ func (c *Config) Validate() error {
 type Table struct {
 	Schema string `db:"table_schema"`
 	Name   string `db:"table_name"`
+	Kind   string `db:"table_kind"` // relkind e.g. v for views
+}
+
+// Replicated reports if changes of table are decoded from WAL; views, materialized views
+// and foreign tables can only be read in full
+func (t Table) Replicated() bool {
+	switch t.Kind {
+	case "v", "m", "f":
+		return false
+	}
+
+	return true
+}
+
+// Stored reports if table stores rows i.e. they have a ctid; views and foreign tables don't
+func (t Table) Stored() bool {
+	switch t.Kind {
+	case "v", "f":
+		return false
+	}
+
+	return true
+}
+
+type Partition struct {
+	Schema     string `db:"table_schema"`
+	Name       string `db:"table_name"`
+	RootSchema string `db:"root_schema"`
+	RootName   string `db:"root_name"`
 }
 
 type ColumnDetails struct {
 	Name       string  `db:"column_name"`
 	DataType   *string `db:"data_type"`
 	IsNullable *string `db:"is_nullable"`
+	UDTName    string  `db:"udt_name"`
+	TypeType   string  `db:"type_type"`        // e.g. e for enums
+	ElementUDT string  `db:"element_udt_name"` // element type of arrays
+	Collation  string  `db:"collation_name"`   // empty for types without collation
+	Precision  *int    `db:"numeric_precision"`
+	Scale      *int    `db:"numeric_scale"`
+}
+
+// orderedBytewise reports if column sorts alike its values compared bytewise e.g. by
+// typeutils.Compare; only uuids and strings of C collation do
+func (c ColumnDetails) orderedBytewise() bool {
+	return c.UDTName == "uuid" || c.Collation == "C" || c.Collation == "POSIX"
 }
@@ -1,6 +1,8 @@
 package driver
 
 import (
+	"strings"
+
 	"github.com/gear5sh/gear5/types"
 )
 
@@ -14,6 +16,7 @@ var pgTypeToDataTypes = map[string]types.DataType{
 	"int":         types.INT64,
 	"int2":        types.INT64,
 	"int4":        types.INT64,
+	"int8":        types.INT64,
 	"serial":      types.INT64,
 	"serial2":     types.INT64,
 	"serial4":     types.INT64,
@@ -21,8 +24,8 @@ var pgTypeToDataTypes = map[string]types.DataType{
 	"bigserial":   types.INT64,
 
 	// numbers
-	"decimal":          types.FLOAT64,
-	"numeric":          types.FLOAT64,
+	"decimal":          types.DECIMAL,
+	"numeric":          types.DECIMAL,
 	"double precision": types.FLOAT64,
 	"float":            types.FLOAT64,
 	"float4":           types.FLOAT64,
@@ -39,6 +42,7 @@ var pgTypeToDataTypes = map[string]types.DataType{
 	"bytea":             types.STRING,
 	"character":         types.STRING,
 	"char":              types.STRING,
+	"bpchar":            types.STRING,
 	"varbit":            types.STRING,
 	"bit":               types.STRING,
 	"bit(n)":            types.STRING,
@@ -55,8 +59,6 @@ var pgTypeToDataTypes = map[string]types.DataType{
 	"hstore":            types.STRING,
 	"name":              types.STRING,
 	"uuid":              types.STRING,
-	"json":              types.STRING,
-	"jsonb":             types.STRING,
 	"line":              types.STRING,
 	"lseg":              types.STRING,
 	"money":             types.STRING,
@@ -70,13 +72,23 @@ var pgTypeToDataTypes = map[string]types.DataType{
 	"enum":              types.STRING,
 	"tsrange":           types.STRING,
 
+	// intervals are kept in postgres text format
+	"interval": types.STRING,
+
+	// json
+	"json":  types.JSON,
+	"jsonb": types.JSON,
+
 	// date/time
-	"time":                        types.TIMESTAMP,
-	"timez":                       types.TIMESTAMP,
-	"date":                        types.TIMESTAMP,
+	"time":                        types.TIME,
+	"timez":                       types.TIME,
+	"timetz":                      types.TIME,
+	"time with time zone":         types.TIME,
+	"time without time zone":      types.TIME,
+	"date":                        types.DATE,
 	"timestamp":                   types.TIMESTAMP,
 	"timestampz":                  types.TIMESTAMP,
-	"interval":                    types.INT64,
+	"timestamptz":                 types.TIMESTAMP,
 	"timestamp with time zone":    types.TIMESTAMP,
 	"timestamp without time zone": types.TIMESTAMP,
 
@@ -84,3 +96,49 @@ var pgTypeToDataTypes = map[string]types.DataType{
 	"ARRAY": types.ARRAY,
 	"array": types.ARRAY,
 }
+
+// dataTypeOf maps a formatted postgres type e.g. "character varying(255)" or "integer[]"
+func dataTypeOf(pgType string) (types.DataType, bool) {
+	if strings.HasSuffix(pgType, "[]") || strings.HasPrefix(pgType, "_") {
+		return types.ARRAY, true
+	}
+
+	// drop type modifiers
+	if i := strings.Index(pgType, "("); i >= 0 {
+		if j := strings.Index(pgType, ")"); j > i {
+			pgType = strings.TrimSpace(pgType[:i] + pgType[j+1:])
+		}
+	}
+
+	datatype, found := pgTypeToDataTypes[pgType]
+	return datatype, found
+}
+
+// propertyOf maps a column discovered from pg_attribute; decimals carry precision and scale
+// and arrays the type of their elements
+func propertyOf(column ColumnDetails) *types.Property {
+	property := &types.Property{}
+	if column.ElementUDT != "" {
+		property.Type = []types.DataType{types.ARRAY}
+		property.Items = propertyOf(ColumnDetails{UDTName: column.ElementUDT, Precision: column.Precision, Scale: column.Scale})
+		return property
+	}
+
+	datatype, found := pgTypeToDataTypes[column.UDTName]
+	switch {
+	case found:
+	case column.TypeType == "e":
+		// enums
+		datatype = types.STRING
+	default:
+		datatype = types.UNKNOWN
+	}
+
+	property.Type = []types.DataType{datatype}
+	if datatype == types.DECIMAL {
+		property.Precision = column.Precision
+		property.Scale = column.Scale
+	}
+
+	return property
+}
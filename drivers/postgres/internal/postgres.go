@@ -14,6 +14,7 @@ import (
 	"github.com/gear5sh/gear5/types"
 	"github.com/gear5sh/gear5/utils"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type Postgres struct {
@@ -24,6 +25,9 @@ type Postgres struct {
 	config      *Config // postgres driver connection config
 	cdcConfig   CDC
 	cdcState    *types.Global[*waljs.WALState]
+	done        <-chan struct{}   // set when streaming continuously
+	partitions  map[string]string // partitions mapped to their root table
+	stored      map[string]bool   // streams of tables storing rows; see Table.Stored
 }
 
 func (p *Postgres) Config() any {
@@ -36,18 +40,19 @@ func (p *Postgres) Spec() any {
 	return Config{}
 }
 
-func (p *Postgres) Check() error {
+// Check validates config and replication resources; resources managed by the driver are
+// only reported as missing since creating a slot retains WAL until it is dropped
+func (p *Postgres) Check(ctx context.Context) error {
 	err := p.config.Validate()
 	if err != nil {
 		return fmt.Errorf("failed to validate config: %s", err)
 	}
 
-	db, err := sqlx.Open("pgx", p.config.Connection.String())
+	db, err := p.connect(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to connect database: %s", err)
+		return err
 	}
-
-	db = db.Unsafe()
+	defer db.Close()
 
 	found, _ := utils.IsOfType(p.config.UpdateMethod, "replication_slot")
 	if found {
@@ -57,13 +62,12 @@ func (p *Postgres) Check() error {
 			return err
 		}
 
-		exists, err := doesReplicationSlotExists(db, cdc.ReplicationSlot)
-		if err != nil {
-			return fmt.Errorf("failed to check replication slot: %s", err)
+		if err := cdc.Validate(); err != nil {
+			return fmt.Errorf("failed to validate cdc config: %s", err)
 		}
 
-		if !exists {
-			return fmt.Errorf("replication slot %s does not exist!", cdc.ReplicationSlot)
+		if err := checkReplication(ctx, db, cdc); err != nil {
+			return err
 		}
 
 		p.Driver.GroupRead = true
@@ -72,26 +76,90 @@ func (p *Postgres) Check() error {
 		logger.Info("Standard Replication is selected")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	return nil
+}
+
+// connect opens a connection pool to database and tests that it works
+func (p *Postgres) connect(ctx context.Context) (*sqlx.DB, error) {
+	db, err := sqlx.Open("pgx", p.config.Connection.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect database: %s", err)
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
 	defer cancel()
 
 	// force a connection and test that it worked
-	err = db.PingContext(ctx)
+	err = db.PingContext(pingCtx)
 	if err != nil {
-		return fmt.Errorf("failed to ping database: %s", err)
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %s", err)
 	}
 
-	p.client = db
+	return db.Unsafe(), nil
+}
+
+// Cleanup drops replication slot and publication created by the driver
+func (p *Postgres) Cleanup(ctx context.Context) error {
+	err := p.config.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate config: %s", err)
+	}
+
+	found, _ := utils.IsOfType(p.config.UpdateMethod, "replication_slot")
+	if !found {
+		logger.Info("Standard Replication is selected; nothing to cleanup")
+		return nil
+	}
+
+	cdc := &CDC{}
+	if err := utils.Unmarshal(p.config.UpdateMethod, cdc); err != nil {
+		return err
+	}
+
+	if err := cdc.Validate(); err != nil {
+		return fmt.Errorf("failed to validate cdc config: %s", err)
+	}
+
+	db, err := sqlx.Open("pgx", p.config.Connection.String())
+	if err != nil {
+		return fmt.Errorf("failed to connect database: %s", err)
+	}
+	defer db.Close()
+
+	if err := dropReplicationSlot(ctx, db, cdc.ReplicationSlot); err != nil {
+		return fmt.Errorf("failed to drop replication slot: %s", err)
+	}
+
+	// publications are only owned by the driver when managed
+	if cdc.ManageSlot && cdc.Plugin == waljs.PgOutput {
+		if _, err := db.ExecContext(ctx, fmt.Sprintf("DROP PUBLICATION IF EXISTS %s", pq.QuoteIdentifier(cdc.Publication))); err != nil {
+			return fmt.Errorf("failed to drop publication: %s", err)
+		}
+	}
+
+	if cdc.ManageSlot && cdc.IncrementalSnapshot {
+		schema, name, _ := strings.Cut(cdc.SignalTable, ".")
+		if _, err := db.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", quoteTable(schema, name))); err != nil {
+			return fmt.Errorf("failed to drop signal table: %s", err)
+		}
+	}
 
 	return nil
 }
 
-func (p *Postgres) Setup() error {
-	if err := p.Check(); err != nil {
+func (p *Postgres) Setup(ctx context.Context) error {
+	if err := p.Check(ctx); err != nil {
+		return err
+	}
+
+	db, err := p.connect(ctx)
+	if err != nil {
 		return err
 	}
+	p.client = db
 
-	return p.loadStreams()
+	return p.loadStreams(ctx)
 }
 
 func (p *Postgres) CloseConnection() {
@@ -103,7 +171,7 @@ func (p *Postgres) CloseConnection() {
 	}
 }
 
-func (p *Postgres) Discover() ([]*types.Stream, error) {
+func (p *Postgres) Discover(ctx context.Context) ([]*types.Stream, error) {
 	streams := []*types.Stream{}
 	for _, stream := range p.SourceStreams {
 		streams = append(streams, stream)
@@ -116,21 +184,26 @@ func (p *Postgres) Type() string {
 	return "Postgres"
 }
 
-func (p *Postgres) Read(stream protocol.Stream, channel chan<- types.Record) error {
+func (p *Postgres) Read(ctx context.Context, stream protocol.Stream, channel chan<- types.Record) error {
 	switch stream.GetSyncMode() {
 	case types.FULLREFRESH:
-		return freshSync(p.client, stream, channel)
+		// tables without primary key are read by page ranges instead of OFFSET pages
+		if p.config.ReaderWorkers > 1 || len(jdbc.FullRefreshKeys(stream)) == 0 {
+			return p.chunkedSync(ctx, stream, channel)
+		}
+
+		return freshSync(ctx, p.client, stream, p.stored[stream.ID()], channel)
 	case types.INCREMENTAL:
 		// read incrementally
-		return p.incrementalSync(stream, channel)
+		return p.incrementalSync(ctx, stream, channel)
 	}
 
 	return nil
 }
 
-func (p *Postgres) loadStreams() error {
+func (p *Postgres) loadStreams(ctx context.Context) error {
 	var tableNamesOutput []Table
-	err := p.client.Select(&tableNamesOutput, getPrivilegedTablesTmpl)
+	err := p.client.SelectContext(ctx, &tableNamesOutput, getPrivilegedTablesTmpl)
 	if err != nil {
 		return fmt.Errorf("failed to retrieve table names: %s", err)
 	}
@@ -139,9 +212,10 @@ func (p *Postgres) loadStreams() error {
 		logger.Warnf("no tables found")
 	}
 
+	p.stored = make(map[string]bool)
 	for _, table := range tableNamesOutput {
 		var columnSchemaOutput []ColumnDetails
-		err := p.client.Select(&columnSchemaOutput, getTableSchemaTmpl, table.Schema, table.Name)
+		err := p.client.SelectContext(ctx, &columnSchemaOutput, getTableSchemaTmpl, table.Schema, table.Name)
 		if err != nil {
 			return fmt.Errorf("failed to retrieve column details for table %s[%s]: %s", table.Name, table.Schema, err)
 		}
@@ -152,7 +226,7 @@ func (p *Postgres) loadStreams() error {
 		}
 
 		var primaryKeyOutput []ColumnDetails
-		err = p.client.Select(&primaryKeyOutput, getTablePrimaryKey, table.Schema, table.Name)
+		err = p.client.SelectContext(ctx, &primaryKeyOutput, getTablePrimaryKey, table.Schema, table.Name)
 		if err != nil {
 			return fmt.Errorf("failed to retrieve primary key columns for table %s[%s]: %s", table.Name, table.Schema, err)
 		}
@@ -161,39 +235,51 @@ func (p *Postgres) loadStreams() error {
 		stream := types.NewStream(table.Name, table.Schema)
 
 		for _, column := range columnSchemaOutput {
-			datatype := types.UNKNOWN
-			if val, found := pgTypeToDataTypes[*column.DataType]; found {
-				datatype = val
-			} else {
+			property := propertyOf(column)
+			if property.DataType() == types.UNKNOWN {
 				logger.Warnf("failed to get respective type in datatypes for column: %s[%s]", column.Name, *column.DataType)
 			}
 
-			stream.UpsertField(column.Name, datatype, strings.EqualFold("yes", *column.IsNullable))
+			stream.UpsertProperty(column.Name, property, strings.EqualFold("yes", *column.IsNullable))
 		}
 
 		// cdc additional fields
-		if p.Driver.GroupRead {
+		if p.Driver.GroupRead && table.Replicated() {
 			for column, typ := range jdbc.CDCColumns {
 				stream.UpsertField(column, typ, true)
 			}
+
+			if p.cdcConfig.IncludeBefore {
+				stream.UpsertField(jdbc.CDCBefore, types.OBJECT, true)
+			}
 		}
 
-		// currently only datetime fields is supported for cursor field, automatic generated fields can also be used
-		// future TODO
-		for propertyName, property := range stream.Schema.Properties {
-			if utils.ExistInArray(property.Type, types.TIMESTAMP) || utils.ExistInArray(property.Type, types.INT64) {
-				stream.WithCursorField(propertyName)
+		// ordered types can be cursor fields e.g. timestamps, sequences and UUIDv7 strings;
+		// composite cursors combine them. Strings are only offered where source orders them
+		// alike cursor comparison i.e. bytewise
+		for _, column := range columnSchemaOutput {
+			switch stream.Schema.Properties[column.Name].DataType() {
+			case types.TIMESTAMP, types.DATE, types.INT64, types.FLOAT64, types.DECIMAL:
+				stream.WithCursorField(column.Name)
+			case types.STRING:
+				if column.orderedBytewise() {
+					stream.WithCursorField(column.Name)
+				}
 			}
 		}
 
-		if !p.Driver.GroupRead {
+		switch {
+		case !p.Driver.GroupRead:
 			stream.WithSyncMode(types.FULLREFRESH)
 			// source has cursor fields, hence incremental also supported
 			if stream.DefaultCursorFields.Len() > 0 {
 				stream.WithSyncMode(types.INCREMENTAL)
 			}
-		} else {
+		case table.Replicated():
 			stream.WithSyncMode(types.CDC)
+		default:
+			// changes of views and foreign tables are not logged
+			stream.WithSyncMode(types.FULLREFRESH)
 		}
 
 		// add primary keys for stream
@@ -203,6 +289,17 @@ func (p *Postgres) loadStreams() error {
 
 		// cache it
 		p.SourceStreams[stream.ID()] = stream
+		p.stored[stream.ID()] = table.Stored()
+	}
+
+	var partitions []Partition
+	if err := p.client.SelectContext(ctx, &partitions, getPartitionsTmpl); err != nil {
+		return fmt.Errorf("failed to retrieve partitions: %s", err)
+	}
+
+	p.partitions = make(map[string]string)
+	for _, partition := range partitions {
+		p.partitions[utils.StreamIdentifier(partition.Name, partition.Schema)] = utils.StreamIdentifier(partition.RootName, partition.RootSchema)
 	}
 
 	return nil
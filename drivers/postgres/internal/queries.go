@@ -1,18 +1,70 @@
 package driver
 
 const (
-	// get all schemas and table
+	// get all schemas and tables with their relkind; partitions are read through their root table
 	getPrivilegedTablesTmpl = `SELECT nspname as table_schema,
-relname as table_name
+relname as table_name,
+relkind as table_kind
 FROM pg_class c
 JOIN pg_namespace n ON c.relnamespace = n.oid
 WHERE has_table_privilege(c.oid, 'SELECT')
 AND has_schema_privilege(current_user, nspname, 'USAGE')
 AND relkind IN ('r', 'm', 'v', 't', 'f', 'p')
+AND NOT c.relispartition  -- Exclude partitions of partitioned tables
 AND nspname NOT LIKE 'pg_%'  -- Exclude default system schemas
 AND nspname != 'information_schema';  -- Exclude information_schema`
-	// get table schema
-	getTableSchemaTmpl = `SELECT column_name, data_type, is_nullable FROM information_schema.columns WHERE table_schema = $1 AND table_name = $2 ORDER BY ordinal_position`
-	// get primary key columns
-	getTablePrimaryKey = `SELECT column_name FROM information_schema.key_column_usage WHERE table_schema = $1 AND table_name = $2 ORDER BY ordinal_position`
+	// get partitions with their root table; sub-partitions are resolved to the topmost table
+	getPartitionsTmpl = `WITH RECURSIVE tree AS (
+SELECT i.inhrelid AS partition, i.inhparent AS root
+FROM pg_inherits i
+JOIN pg_class p ON i.inhparent = p.oid
+WHERE p.relkind = 'p' AND NOT p.relispartition
+UNION ALL
+SELECT i.inhrelid, tree.root
+FROM pg_inherits i
+JOIN tree ON i.inhparent = tree.partition
+)
+SELECT n.nspname AS table_schema, c.relname AS table_name, rn.nspname AS root_schema, r.relname AS root_name
+FROM tree
+JOIN pg_class c ON tree.partition = c.oid
+JOIN pg_namespace n ON c.relnamespace = n.oid
+JOIN pg_class r ON tree.root = r.oid
+JOIN pg_namespace rn ON r.relnamespace = rn.oid
+WHERE c.relispartition`
+	// get table schema; precision and scale are decoded from type modifier of numeric and its arrays
+	getTableSchemaTmpl = `SELECT a.attname AS column_name,
+format_type(a.atttypid, a.atttypmod) AS data_type,
+t.typname AS udt_name,
+t.typtype AS type_type,
+COALESCE(et.typname, '') AS element_udt_name,
+COALESCE(co.collname, '') AS collation_name,
+CASE WHEN a.attnotnull THEN 'NO' ELSE 'YES' END AS is_nullable,
+CASE WHEN t.typname IN ('numeric', '_numeric') AND a.atttypmod >= 4 THEN ((a.atttypmod - 4) >> 16) & 65535 END AS numeric_precision,
+CASE WHEN t.typname IN ('numeric', '_numeric') AND a.atttypmod >= 4 THEN (a.atttypmod - 4) & 65535 END AS numeric_scale
+FROM pg_attribute a
+JOIN pg_class c ON a.attrelid = c.oid
+JOIN pg_namespace n ON c.relnamespace = n.oid
+JOIN pg_type t ON a.atttypid = t.oid
+LEFT JOIN pg_type et ON t.typelem = et.oid AND t.typcategory = 'A'
+LEFT JOIN pg_collation co ON a.attcollation = co.oid
+WHERE n.nspname = $1 AND c.relname = $2 AND a.attnum > 0 AND NOT a.attisdropped
+ORDER BY a.attnum`
+	// get primary key columns in key order
+	getTablePrimaryKey = `SELECT a.attname AS column_name
+FROM pg_constraint con
+JOIN pg_class c ON con.conrelid = c.oid
+JOIN pg_namespace n ON c.relnamespace = n.oid
+CROSS JOIN LATERAL unnest(con.conkey) WITH ORDINALITY AS k(attnum, position)
+JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum = k.attnum
+WHERE con.contype = 'p' AND n.nspname = $1 AND c.relname = $2
+ORDER BY k.position`
+	// get number of pages of a relation; zero for views
+	getRelationPagesTmpl = `SELECT (pg_relation_size(c.oid) / current_setting('block_size')::bigint)::bigint FROM pg_class c JOIN pg_namespace n ON c.relnamespace = n.oid WHERE n.nspname = $1 AND c.relname = $2`
+	// get tables added to a publication; partitioned tables are listed instead of their partitions
+	getPublicationTablesTmpl = `SELECT n.nspname AS table_schema, c.relname AS table_name
+FROM pg_publication_rel pr
+JOIN pg_publication p ON pr.prpubid = p.oid
+JOIN pg_class c ON pr.prrelid = c.oid
+JOIN pg_namespace n ON c.relnamespace = n.oid
+WHERE p.pubname = $1`
 )
@@ -15,9 +15,12 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
-// Simple Full Refresh Sync; Loads table fully
-func freshSync(client *sqlx.DB, stream protocol.Stream, channel chan<- types.Record) error {
-	tx, err := client.BeginTx(context.TODO(), &sql.TxOptions{
+// Simple Full Refresh Sync; Loads table fully. Tables with a primary key are loaded in key
+// order with the key of every batch checkpointed into stream state; an interrupted load
+// resumes past it on a new snapshot. Relations without primary key e.g. views are paged by
+// OFFSET in jdbc.PostgresRowOrder within the snapshot and load from scratch once interrupted
+func freshSync(ctx context.Context, client *sqlx.DB, stream protocol.Stream, stored bool, channel chan<- types.Record) error {
+	tx, err := client.BeginTx(ctx, &sql.TxOptions{
 		Isolation: sql.LevelRepeatableRead,
 	})
 	if err != nil {
@@ -26,12 +29,41 @@ func freshSync(client *sqlx.DB, stream protocol.Stream, channel chan<- types.Rec
 
 	defer tx.Rollback()
 
-	stmt := jdbc.PostgresFullRefresh(stream)
+	stmt, args, err := jdbc.PostgresFullRefresh(stream)
+	if err != nil {
+		return err
+	}
 
-	setter := jdbc.NewReader(context.TODO(), stmt, int(stream.BatchSize()), func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
-		return tx.Query(query, args...)
-	})
-	return setter.Capture(func(rows *sql.Rows) error {
+	setter := jdbc.NewReader(ctx, stmt, int(stream.BatchSize()), func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+		return tx.QueryContext(ctx, query, args...)
+	}, args...)
+	if keys := jdbc.FullRefreshKeys(stream); len(keys) > 0 {
+		setter.WithKeyset(keys, jdbc.RowKey(keys))
+
+		after, err := resumeKey(stream, keys, channel)
+		if err != nil {
+			return err
+		}
+		if after != nil {
+			setter.WithStartKey(after)
+		}
+
+		setter.WithCheckpoint(func(key []any) error {
+			progress := fullLoadState{}
+			for _, value := range key {
+				progress.After = append(progress.After, jdbc.KeyString(value))
+			}
+
+			safego.Insert(channel, types.Record{Checkpoint: func() {
+				stream.SetStateKey(fullLoadStateKey, progress)
+			}})
+			return nil
+		})
+	} else {
+		setter.WithOffset(jdbc.PostgresRowOrder(stream, stored))
+	}
+
+	err = setter.Capture(func(rows *sql.Rows) error {
 		// Create a map to hold column names and values
 		record := make(types.RecordData)
 
@@ -49,11 +81,49 @@ func freshSync(client *sqlx.DB, stream protocol.Stream, channel chan<- types.Rec
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// next full refresh starts over
+	safego.Insert(channel, types.Record{Checkpoint: func() {
+		stream.SetStateKey(fullLoadStateKey, nil)
+	}})
+
+	return nil
+}
+
+// resumeKey returns primary key checkpointed by an interrupted full load; nil if the
+// load starts over
+func resumeKey(stream protocol.Stream, keys []string, channel chan<- types.Record) ([]any, error) {
+	previous := stream.GetStateKey(fullLoadStateKey)
+	if previous == nil {
+		return nil, nil
+	}
+
+	saved := &fullLoadState{}
+	if err := utils.Unmarshal(previous, saved); err != nil {
+		return nil, fmt.Errorf("failed to parse full load state: %s", err)
+	}
+
+	if len(saved.After) != len(keys) {
+		logger.Warnf("Full load state of %s doesn't match primary key %v; restarting", stream.ID(), keys)
+		return nil, restartFullLoad(stream, channel)
+	}
+
+	logger.Infof("Resuming full load of %s after %v", stream.ID(), saved.After)
+
+	after := []any{}
+	for _, value := range saved.After {
+		after = append(after, value)
+	}
+
+	return after, nil
 }
 
 // Incremental Sync based on a Cursor Value
-func (p *Postgres) incrementalSync(stream protocol.Stream, channel chan<- types.Record) error {
-	tx, err := p.client.BeginTx(context.TODO(), &sql.TxOptions{
+func (p *Postgres) incrementalSync(ctx context.Context, stream protocol.Stream, channel chan<- types.Record) error {
+	tx, err := p.client.BeginTx(ctx, &sql.TxOptions{
 		Isolation: sql.LevelRepeatableRead,
 	})
 	if err != nil {
@@ -63,17 +133,22 @@ func (p *Postgres) incrementalSync(stream protocol.Stream, channel chan<- types.
 	defer tx.Rollback()
 
 	intialState := stream.InitialState()
-	args := []any{}
-	statement := jdbc.PostgresWithoutState(stream)
+	statement, args, err := jdbc.PostgresWithoutState(stream)
 	if intialState != nil {
 		logger.Debugf("Using Initial state for stream %s : %v", stream.ID(), intialState)
-		statement = jdbc.PostgresWithState(stream)
-		args = append(args, intialState)
+		statement, args, err = jdbc.PostgresWithState(stream, intialState)
+	}
+	if err != nil {
+		return err
 	}
 
-	setter := jdbc.NewReader(context.Background(), statement, int(stream.BatchSize()), func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
-		return tx.Query(query, args...)
+	setter := jdbc.NewReader(ctx, statement, int(stream.BatchSize()), func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+		return tx.QueryContext(ctx, query, args...)
 	}, args...)
+	if keys := jdbc.IncrementalKeys(stream); len(keys) > 0 {
+		setter.WithKeyset(keys, jdbc.RowKey(keys))
+	}
+
 	return setter.Capture(func(rows *sql.Rows) error {
 		// Create a map to hold column names and values
 		record := make(types.RecordData)
@@ -12,8 +12,8 @@ func main() {
 	driver := &driver.Postgres{
 		Driver: base.NewBase(),
 	}
-	_ = protocol.BulkDriver(driver)
+	_ = protocol.BulkDriverV2(driver)
 
 	defer driver.CloseConnection()
-	gear5.RegisterDriver(driver)
+	gear5.RegisterDriverV2(driver)
 }
@@ -1,6 +1,7 @@
 package driver
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -111,7 +112,7 @@ func (s *S3) Type() string {
 
 // NOTE: S3 read doesn't perform neccessary checks such as matching cursor field present in stream since
 // it works only on single cursor field
-func (s *S3) Read(stream protocol.Stream, channel chan<- types.Record) error {
+func (s *S3) Read(ctx context.Context, stream protocol.Stream, channel chan<- types.Record) error {
 	name, namespace := stream.Name(), stream.Namespace()
 	// get pattern from stream name
 	pattern := s.config.Streams[name]
@@ -133,6 +134,11 @@ func (s *S3) Read(stream protocol.Stream, channel chan<- types.Record) error {
 	}
 
 	err := s.iteration(types.ToPtr(stream.BatchSize()), pattern, s.config.PreLoadFactor, func(reader reader.Reader, file *s3.Object) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			// discontinue iteration
+			return false, err
+		}
+
 		if localCursor != nil && file.LastModified.Before(*localCursor) {
 			// continue iteration
 			return true, nil
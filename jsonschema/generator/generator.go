@@ -379,6 +379,17 @@ func (g *JSONSchemaGenerator) generateSchemaForExpr(ownerDecl *declInfo, fieldEx
 		case *ast.Ident:
 			g.LogVerbose(fmt.Sprintf("field type is ident: %s, %s", fieldType.Name, ownerDecl.defKey))
 
+			// any is the predeclared alias of interface{}
+			if fieldType.Name == "any" {
+				if field != nil {
+					generatedSchema, err = g.generateInterfaceSchemaForField(ownerDecl, field, parentKey)
+					break
+				}
+
+				generatedSchema, err = g.generateInterfaceSchemaForDecl(ownerDecl, parentKey)
+				break
+			}
+
 			if simpleSchema, ok, err = g.generateSchemaForBuiltIn(fieldType.Name, field, parentKey); ok {
 				generatedSchema = simpleSchema
 				break
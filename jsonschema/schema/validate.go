@@ -0,0 +1,254 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// validatedKeywords are checked by Validate; annotations are ignored and schemas using
+// any other keyword the generator may emit e.g. pattern or oneOf are rejected
+var (
+	validatedKeywords = []string{"$ref", "type", "enum", "const", "properties", "required", "additionalProperties",
+		"items", "minimum", "maximum", "minLength", "maxLength"}
+	annotations = []string{"$schema", "id", "title", "description", "default", "definitions", "x-go-path"}
+)
+
+// Validate validates a JSON document against a JSON schema as generated by this package
+// limited to validatedKeywords. Types may be listed comma separated as set by annotations
+// and optional properties may be null
+func Validate(schema, document []byte) error {
+	var root map[string]any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return fmt.Errorf("failed to parse schema: %s", err)
+	}
+
+	if err := checkKeywords("#", root); err != nil {
+		return err
+	}
+
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("failed to parse document: %s", err)
+	}
+
+	return (&validator{root: root}).validate("$", root, value)
+}
+
+type validator struct {
+	root map[string]any
+}
+
+func (v *validator) validate(path string, schema map[string]any, value any) error {
+	if ref, found := schema["$ref"].(string); found {
+		resolved, err := v.resolve(ref)
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+
+		return v.validate(path, resolved, value)
+	}
+
+	if err := checkType(schema["type"], value); err != nil {
+		return fmt.Errorf("%s: %s", path, err)
+	}
+
+	if enum, found := schema["enum"].([]any); found && !contains(enum, value) {
+		return fmt.Errorf("%s: %v is not one of %v", path, value, enum)
+	}
+
+	if constant, found := schema["const"]; found && !contains([]any{constant}, value) {
+		return fmt.Errorf("%s: %v is not %v", path, value, constant)
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		return v.validateObject(path, schema, value)
+	case []any:
+		items, found := schema["items"].(map[string]any)
+		if !found {
+			return nil
+		}
+
+		for i, item := range value {
+			if err := v.validate(fmt.Sprintf("%s[%d]", path, i), items, item); err != nil {
+				return err
+			}
+		}
+	case json.Number:
+		number, err := value.Float64()
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+
+		if minimum, found := schema["minimum"].(float64); found && number < minimum {
+			return fmt.Errorf("%s: %v is less than %v", path, value, minimum)
+		}
+
+		if maximum, found := schema["maximum"].(float64); found && number > maximum {
+			return fmt.Errorf("%s: %v is greater than %v", path, value, maximum)
+		}
+	case string:
+		if minLength, found := schema["minLength"].(float64); found && float64(len([]rune(value))) < minLength {
+			return fmt.Errorf("%s: %q is shorter than %v", path, value, minLength)
+		}
+
+		if maxLength, found := schema["maxLength"].(float64); found && float64(len([]rune(value))) > maxLength {
+			return fmt.Errorf("%s: %q is longer than %v", path, value, maxLength)
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) validateObject(path string, schema map[string]any, object map[string]any) error {
+	required, _ := schema["required"].([]any)
+	for _, name := range required {
+		if _, found := object[fmt.Sprint(name)]; !found {
+			return fmt.Errorf("%s: property %s is required", path, name)
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	for name, value := range object {
+		property, found := properties[name].(map[string]any)
+		if !found {
+			if additional, found := schema["additionalProperties"].(bool); found && !additional {
+				return fmt.Errorf("%s: property %s is not allowed", path, name)
+			}
+
+			continue
+		}
+
+		if value == nil && !contains(required, name) {
+			continue
+		}
+
+		if err := v.validate(fmt.Sprintf("%s.%s", path, name), property, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolve returns definition of a local ref e.g. #/definitions/name
+func (v *validator) resolve(ref string) (map[string]any, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported ref %s", ref)
+	}
+
+	var current any = v.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolved ref %s", ref)
+		}
+
+		current = object[part]
+	}
+
+	resolved, ok := current.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unresolved ref %s", ref)
+	}
+
+	return resolved, nil
+}
+
+func checkType(typ any, value any) error {
+	allowed := []string{}
+	switch typ := typ.(type) {
+	case nil:
+		return nil
+	case string:
+		allowed = strings.Split(typ, ",")
+	case []any:
+		for _, t := range typ {
+			allowed = append(allowed, fmt.Sprint(t))
+		}
+	}
+
+	actual := typeOf(value)
+	for _, t := range allowed {
+		t = strings.TrimSpace(t)
+		if t == actual || (t == "number" && actual == "integer") {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("expected %s but found %s", strings.Join(allowed, " or "), actual)
+}
+
+func typeOf(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case json.Number:
+		if number, err := value.Float64(); err == nil && number == math.Trunc(number) && !strings.ContainsAny(value.String(), ".eE") {
+			return "integer"
+		}
+
+		return "number"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+func contains(values []any, value any) bool {
+	for _, candidate := range values {
+		if fmt.Sprint(candidate) == fmt.Sprint(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkKeywords fails on keywords of schema and its subschemas not supported by Validate
+func checkKeywords(path string, schema map[string]any) error {
+	for keyword, value := range schema {
+		if !slices.Contains(validatedKeywords, keyword) && !slices.Contains(annotations, keyword) {
+			return fmt.Errorf("schema %s: keyword %s is not supported", path, keyword)
+		}
+
+		subschemas := map[string]any{}
+		switch keyword {
+		case "properties", "definitions":
+			subschemas, _ = value.(map[string]any)
+		case "items":
+			subschemas[""] = value
+		case "additionalProperties":
+			if _, ok := value.(bool); !ok {
+				return fmt.Errorf("schema %s: only boolean additionalProperties are supported", path)
+			}
+		}
+
+		for name, subschema := range subschemas {
+			object, ok := subschema.(map[string]any)
+			if !ok {
+				return fmt.Errorf("schema %s/%s: expected schema object", path, strings.TrimSuffix(keyword+"/"+name, "/"))
+			}
+
+			if err := checkKeywords(strings.TrimSuffix(path+"/"+keyword+"/"+name, "/"), object); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		keyword string
+		schema  string
+		valid   []string
+		invalid []string
+	}{
+		{"type", `{"type": "integer"}`, []string{`1`, `-3`}, []string{`1.5`, `"1"`, `null`}},
+		{"type listed", `{"type": "string,null"}`, []string{`"a"`, `null`}, []string{`1`}},
+		{"type array", `{"type": ["number", "boolean"]}`, []string{`1`, `1.5`, `true`}, []string{`"1"`}},
+		{"enum", `{"enum": ["STREAM", "GLOBAL"]}`, []string{`"STREAM"`}, []string{`"STREAMS"`}},
+		{"const", `{"const": 1}`, []string{`1`}, []string{`2`}},
+		{"properties", `{"properties": {"id": {"type": "integer"}}}`, []string{`{"id": 1}`, `{"id": null}`, `{}`}, []string{`{"id": "1"}`}},
+		{"required", `{"properties": {"id": {"type": "integer"}}, "required": ["id"]}`, []string{`{"id": 1}`}, []string{`{}`, `{"id": null}`}},
+		{"additionalProperties", `{"properties": {"id": {}}, "additionalProperties": false}`, []string{`{"id": 1}`}, []string{`{"name": "a"}`}},
+		{"items", `{"items": {"type": "string"}}`, []string{`[]`, `["a", "b"]`}, []string{`["a", 1]`}},
+		{"minimum", `{"minimum": 1}`, []string{`1`, `2.5`}, []string{`0.5`}},
+		{"maximum", `{"maximum": 1}`, []string{`1`, `-2`}, []string{`1.5`}},
+		{"minLength", `{"minLength": 2}`, []string{`"ab"`, `"éé"`}, []string{`"é"`}},
+		{"maxLength", `{"maxLength": 2}`, []string{`"ab"`, `"éé"`}, []string{`"abc"`}},
+		{"$ref", `{"definitions": {"id": {"type": "integer"}}, "properties": {"id": {"$ref": "#/definitions/id"}}}`, []string{`{"id": 1}`}, []string{`{"id": "1"}`}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.keyword, func(t *testing.T) {
+			for _, document := range test.valid {
+				assert.NoError(t, Validate([]byte(test.schema), []byte(document)), document)
+			}
+
+			for _, document := range test.invalid {
+				assert.Error(t, Validate([]byte(test.schema), []byte(document)), document)
+			}
+		})
+	}
+}
+
+func TestValidateUnsupportedKeywords(t *testing.T) {
+	for _, schema := range []string{
+		`{"pattern": "^a"}`,
+		`{"properties": {"name": {"type": "string", "format": "email"}}}`,
+		`{"items": {"oneOf": [{"type": "string"}]}}`,
+		`{"definitions": {"id": {"multipleOf": 2}}}`,
+		`{"additionalProperties": {"type": "string"}}`,
+		`{"items": [{"type": "string"}]}`,
+	} {
+		assert.Error(t, Validate([]byte(schema), []byte(`"a"`)), schema)
+	}
+
+	assert.NoError(t, Validate([]byte(`{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "t", "description": "d", "default": 1, "x-go-path": "p"}`), []byte(`1`)))
+}
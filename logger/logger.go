@@ -99,6 +99,28 @@ func LogCatalog(streams []*types.Stream) {
 	}
 }
 
+func LogAction(action *types.ActionRow) {
+	message := types.Message{}
+	message.Type = types.ActionMessage
+	message.Action = action
+
+	err := console.Print(console.INFO, message)
+	if err != nil {
+		Fatalf("failed to encode action %v: %s", action, err)
+	}
+}
+
+func LogConfiguredCatalog(catalog *types.Catalog) {
+	message := types.Message{}
+	message.Type = types.CataLogMessage
+	message.Catalog = catalog
+	Info("logging catalog")
+	err := console.Print(console.INFO, message)
+	if err != nil {
+		Fatalf("failed to encode catalog %v: %s", catalog, err)
+	}
+}
+
 func LogConnectionStatus(err error) {
 	message := types.Message{}
 	message.Type = types.ConnectionStatusMessage
@@ -134,10 +156,15 @@ func LogRequest(req *http.Request) {
 	fmt.Println(string(requestDump))
 }
 
+// LogState logs state unless it is empty; state is locked against concurrent updates of streams
 func LogState(state *types.State) {
 	state.Lock()
 	defer state.Unlock()
 
+	if state.IsZero() {
+		return
+	}
+
 	message := types.Message{}
 	message.Type = types.StateMessage
 	message.State = state
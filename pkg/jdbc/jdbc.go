@@ -1,35 +1,303 @@
 package jdbc
 
 import (
+	"database/sql"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gear5sh/gear5/protocol"
 	"github.com/gear5sh/gear5/types"
+	"github.com/gear5sh/gear5/utils"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const CDCDeletedAt = "_cdc_deleted_at"
 const CDCLSN = "_cdc_lsn"
 const CDCUpdatedAt = "_cdc_updated_at"
+const CDCOp = "_cdc_op"
+const CDCXid = "_cdc_xid"
+
+// previous row of updates and deletes; only added when enabled
+const CDCBefore = "_cdc_before"
 
 var CDCColumns = map[string]types.DataType{
 	CDCDeletedAt: types.TIMESTAMP,
 	CDCLSN:       types.STRING,
 	CDCUpdatedAt: types.TIMESTAMP,
+	CDCOp:        types.STRING,
+	CDCXid:       types.INT64,
+}
+
+// Order by Cursor; unordered if stream has no cursor e.g. CDC snapshots
+func PostgresWithoutState(stream protocol.Stream) (string, []any, error) {
+	args := arguments{}
+	condition, err := where(stream, &args)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM "%s"."%s"%s`, projection(stream), stream.Namespace(), stream.Name(), condition)
+	if fields := stream.Self().CursorFields(); len(fields) > 0 {
+		query = fmt.Sprintf(`%s ORDER BY %s`, query, quote(fields...))
+	}
+
+	return query, args, nil
+}
+
+// Order by Cursor; composite cursors are compared as a row with state holding a value per field
+func PostgresWithState(stream protocol.Stream, state any) (string, []any, error) {
+	fields := stream.Self().CursorFields()
+	values := []any{state}
+	if len(fields) > 1 {
+		tuple, ok := state.([]any)
+		if !ok || len(tuple) != len(fields) {
+			return "", nil, fmt.Errorf("state of %s must hold values of cursor fields %v: %v", stream.ID(), fields, state)
+		}
+
+		values = tuple
+	}
+
+	args := arguments{}
+	placeholders := []string{}
+	for _, value := range values {
+		placeholders = append(placeholders, args.bind(value))
+	}
+
+	condition, err := where(stream, &args, fmt.Sprintf("(%s) > (%s)", quote(fields...), strings.Join(placeholders, ", ")))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return fmt.Sprintf(`SELECT %s FROM "%s"."%s"%s ORDER BY %s`, projection(stream), stream.Namespace(), stream.Name(), condition, ascending(fields...)), args, nil
+}
+
+// Order by primary keys; unordered without primary key as Reader then pages in PostgresRowOrder
+func PostgresFullRefresh(stream protocol.Stream) (string, []any, error) {
+	args := arguments{}
+	condition, err := where(stream, &args)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM "%s"."%s"%s`, projection(stream), stream.Namespace(), stream.Name(), condition)
+	if keys := FullRefreshKeys(stream); len(keys) > 0 {
+		query = fmt.Sprintf(`%s ORDER BY %s`, query, quote(keys...))
+	}
+
+	return query, args, nil
+}
+
+// PostgresRowOrder orders rows of a relation without primary key for OFFSET pages; rows
+// stored by relation are ordered by location and rows of others e.g. views by their text,
+// which is total up to identical rows
+func PostgresRowOrder(stream protocol.Stream, stored bool) string {
+	if stored {
+		// partitions of partitioned tables share locations
+		return "tableoid, ctid"
+	}
+
+	return fmt.Sprintf(`ROW("%s"."%s".*)::text`, stream.Namespace(), stream.Name())
+}
+
+// Rows of a page range i.e. ctid >= start; bounded to ctid < end unless end is empty
+func PostgresChunk(stream protocol.Stream, start, end string) (string, []any, error) {
+	args := arguments{}
+	conditions := []string{fmt.Sprintf("ctid >= %s::tid", args.bind(start))}
+	if end != "" {
+		conditions = append(conditions, fmt.Sprintf("ctid < %s::tid", args.bind(end)))
+	}
+
+	condition, err := where(stream, &args, conditions...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return fmt.Sprintf(`SELECT %s FROM "%s"."%s"%s`, projection(stream), stream.Namespace(), stream.Name(), condition), args, nil
+}
+
+// Chunk of rows ordered by primary keys; starts past primary key after unless empty
+func PostgresKeyChunk(stream protocol.Stream, after []string, limit int) (string, []any, error) {
+	keys := FullRefreshKeys(stream)
+
+	args := arguments{}
+	conditions := []string{}
+	if len(after) > 0 {
+		placeholders := []string{}
+		for _, value := range after {
+			placeholders = append(placeholders, args.bind(value))
+		}
+
+		conditions = append(conditions, fmt.Sprintf("(%s) > (%s)", quote(keys...), strings.Join(placeholders, ", ")))
+	}
+
+	condition, err := where(stream, &args, conditions...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM "%s"."%s"%s ORDER BY %s LIMIT %s`, projection(stream), stream.Namespace(), stream.Name(), condition, quote(keys...), args.bind(limit))
+
+	return query, args, nil
+}
+
+// Keyset of full refresh; empty if stream has no primary key
+func FullRefreshKeys(stream protocol.Stream) []string {
+	keys := stream.GetStream().SourceDefinedPrimaryKey.Array()
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Keyset of incremental sync i.e. cursor fields followed by primary keys; empty if
+// a cursor field is nullable or stream has no primary key since rows can't be ordered uniquely
+func IncrementalKeys(stream protocol.Stream) []string {
+	primaryKey := FullRefreshKeys(stream)
+	if len(primaryKey) == 0 || stream.Schema() == nil {
+		return nil
+	}
+
+	fields := stream.Self().CursorFields()
+	for _, field := range fields {
+		property, found := stream.Schema().Properties[field]
+		if !found || property.Nullable() {
+			return nil
+		}
+	}
+
+	keys := append([]string{}, fields...)
+	for _, key := range primaryKey {
+		if !utils.ExistInArray(fields, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// RowKey returns values of columns from current row of rows
+func RowKey(columns []string) func(rows *sql.Rows) ([]any, error) {
+	return func(rows *sql.Rows) ([]any, error) {
+		names, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]any, len(names))
+		pointers := make([]any, len(names))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		key := []any{}
+		for _, column := range columns {
+			i, found := utils.ArrayContains(names, func(name string) bool {
+				return name == column
+			})
+			if !found {
+				return nil, fmt.Errorf("key column %s missing from rows", column)
+			}
+
+			key = append(key, values[i])
+		}
+
+		return key, nil
+	}
+}
+
+// KeyString formats values of common key types alike whether read by a query or decoded
+// from WAL; formatted values are accepted by postgres as input of their type
+func KeyString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return fmt.Sprintf("\\x%x", v)
+	case [16]byte:
+		return fmt.Sprintf("%x-%x-%x-%x-%x", v[0:4], v[4:6], v[6:8], v[8:10], v[10:16])
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case pgtype.Numeric:
+		if text, err := v.Value(); err == nil {
+			return fmt.Sprint(text)
+		}
+	}
+
+	return fmt.Sprint(value)
 }
 
-// Order by Cursor
-func PostgresWithoutState(stream protocol.Stream) string {
-	return fmt.Sprintf(`SELECT * FROM "%s"."%s" ORDER BY %s`, stream.Namespace(), stream.Name(), stream.Cursor())
+// arguments of a query bound to placeholders in order
+type arguments []any
+
+func (a *arguments) bind(value any) string {
+	*a = append(*a, value)
+	return fmt.Sprintf("$%d", len(*a))
 }
 
-// Order by Cursor
-func PostgresWithState(stream protocol.Stream) string {
-	return fmt.Sprintf(`SELECT * FROM "%s"."%s" where "%s">$1 ORDER BY "%s" ASC NULLS FIRST`, stream.Namespace(), stream.Name(), stream.Cursor(), stream.Cursor())
+// where joins conditions and filter of stream into a WHERE clause; empty without conditions
+func where(stream protocol.Stream, args *arguments, conditions ...string) (string, error) {
+	filter, err := stream.Self().RowFilter()
+	if err != nil {
+		return "", fmt.Errorf("invalid filter of %s: %s", stream.ID(), err)
+	}
+
+	if filter != nil {
+		conditions = append(conditions, filter.SQL(args.bind))
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	return fmt.Sprintf(" WHERE %s", strings.Join(conditions, " AND ")), nil
 }
 
-// Order by primary keys
-func PostgresFullRefresh(stream protocol.Stream) string {
-	return fmt.Sprintf(`SELECT * FROM "%s"."%s" ORDER BY %s`, stream.Namespace(), stream.Name(),
-		strings.Join(stream.GetStream().SourceDefinedPrimaryKey.Array(), ", "))
+// Columns selected for stream i.e. columns of schema read from source; all columns are
+// selected unless some are excluded
+func projection(stream protocol.Stream) string {
+	excluded := stream.Self().ExcludeColumns
+	if len(excluded) == 0 || stream.Schema() == nil {
+		return "*"
+	}
+
+	columns := []string{}
+	for column := range stream.Schema().Properties {
+		if _, found := CDCColumns[column]; found || column == CDCBefore || utils.ExistInArray(excluded, column) {
+			continue
+		}
+
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	return quote(columns...)
+}
+
+// ascending orders columns with nulls first
+func ascending(columns ...string) string {
+	ordered := []string{}
+	for _, column := range columns {
+		ordered = append(ordered, fmt.Sprintf(`"%s" ASC NULLS FIRST`, column))
+	}
+
+	return strings.Join(ordered, ", ")
+}
+
+func quote(columns ...string) string {
+	quoted := []string{}
+	for _, column := range columns {
+		quoted = append(quoted, fmt.Sprintf(`"%s"`, column))
+	}
+
+	return strings.Join(quoted, ", ")
 }
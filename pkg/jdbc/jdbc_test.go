@@ -0,0 +1,57 @@
+package jdbc
+
+import (
+	"testing"
+
+	"github.com/gear5sh/gear5/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStream(primaryKey ...string) *types.ConfiguredStream {
+	return &types.ConfiguredStream{
+		Stream: &types.Stream{
+			Name:                    "users",
+			Namespace:               "public",
+			SourceDefinedPrimaryKey: types.NewSet(primaryKey...),
+		},
+	}
+}
+
+func TestPostgresFullRefresh(t *testing.T) {
+	query, args, err := PostgresFullRefresh(newStream("tenant_id", "id"))
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM "public"."users" ORDER BY "id", "tenant_id"`, query)
+	assert.Empty(t, args)
+
+	// rows without primary key are paged by Reader in PostgresRowOrder
+	query, _, err = PostgresFullRefresh(newStream())
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM "public"."users"`, query)
+}
+
+func TestPostgresRowOrder(t *testing.T) {
+	assert.Equal(t, "tableoid, ctid", PostgresRowOrder(newStream(), true))
+	assert.Equal(t, `ROW("public"."users".*)::text`, PostgresRowOrder(newStream(), false))
+}
+
+func TestPostgresWithoutState(t *testing.T) {
+	stream := newStream("id")
+	stream.CursorField = "updated_at,id"
+
+	query, _, err := PostgresWithoutState(stream)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM "public"."users" ORDER BY "updated_at", "id"`, query)
+
+	// streams without cursor e.g. of CDC snapshots
+	query, _, err = PostgresWithoutState(newStream())
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM "public"."users"`, query)
+
+	stream = newStream()
+	stream.Filter = "tenant_id = 42"
+	query, args, err := PostgresWithoutState(stream)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM "public"."users" WHERE "tenant_id" = $1`, query)
+	assert.Equal(t, []any{"42"}, args)
+}
@@ -13,12 +13,23 @@ type Reader[T types.Iterable] struct {
 	query     string
 	args      []any
 	batchSize int
-	offset    int
 	err       chan error
 	rows      chan T
 	closed    bool
 	ctx       context.Context
 
+	// keyset pagination; LIMIT/OFFSET pagination in order is the fallback without keys and
+	// the base query is read in a single pass without either since pages of a query lacking
+	// a total order may skip or repeat rows
+	keys    []string
+	lastKey []any
+	key     func(T) ([]any, error)
+	order   string
+	offset  int
+
+	// called with key of the last row of every full page once the page is captured
+	checkpoint func(key []any) error
+
 	exec func(ctx context.Context, query string, args ...any) (T, error)
 }
 
@@ -27,7 +38,6 @@ func NewReader[T types.Iterable](ctx context.Context, baseQuery string, batchSiz
 	setter := &Reader[T]{
 		query:     baseQuery,
 		batchSize: batchSize,
-		offset:    0,
 		err:       make(chan error),
 		rows:      make(chan T),
 		ctx:       ctx,
@@ -38,6 +48,39 @@ func NewReader[T types.Iterable](ctx context.Context, baseQuery string, batchSiz
 	return setter
 }
 
+// WithKeyset pages on columns instead of OFFSET; key returns values of columns
+// for the current row. Columns must be unique and non-nullable together
+func (o *Reader[T]) WithKeyset(columns []string, key func(T) ([]any, error)) *Reader[T] {
+	o.keys = columns
+	o.key = key
+
+	return o
+}
+
+// WithOffset pages with LIMIT/OFFSET in order appended to base query i.e. base query must
+// not be ordered; order must be total for rows of a snapshot e.g. ctid, and pages must be
+// read within a single snapshot
+func (o *Reader[T]) WithOffset(order string) *Reader[T] {
+	o.order = order
+
+	return o
+}
+
+// WithStartKey starts keyset pagination past key e.g. to resume an interrupted read
+func (o *Reader[T]) WithStartKey(key []any) *Reader[T] {
+	o.lastKey = key
+
+	return o
+}
+
+// WithCheckpoint calls checkpoint with key of the last row of every full page after
+// the page is captured; requires keyset pagination
+func (o *Reader[T]) WithCheckpoint(checkpoint func(key []any) error) *Reader[T] {
+	o.checkpoint = checkpoint
+
+	return o
+}
+
 func (o *Reader[T]) Close() {
 	o.closed = true
 	safego.Close(o.err)
@@ -51,14 +94,32 @@ func (o *Reader[T]) Capture(onCapture func(T) error) error {
 		return fmt.Errorf("base query ends with ';': %s", o.query)
 	}
 
+	if o.batchSize <= 0 {
+		return fmt.Errorf("invalid batch size: %d", o.batchSize)
+	}
+
 	for {
-		rows, err := o.exec(o.ctx, o.query, o.args...)
+		// pages already captured stay consistent with their checkpoints
+		if err := o.ctx.Err(); err != nil {
+			return err
+		}
+
+		query, args := o.page()
+		rows, err := o.exec(o.ctx, query, args...)
 		if err != nil {
 			return err
 		}
 
 		length := 0
 		for rows.Next() {
+			// key of the last row of a page is where the next page starts
+			if o.key != nil && length == o.batchSize-1 {
+				o.lastKey, err = o.key(rows)
+				if err != nil {
+					return fmt.Errorf("failed to read page key: %s", err)
+				}
+			}
+
 			err := onCapture(rows)
 			if err != nil {
 				return err
@@ -72,10 +133,47 @@ func (o *Reader[T]) Capture(onCapture func(T) error) error {
 			return err
 		}
 
-		if length != o.batchSize {
+		if (len(o.keys) == 0 && o.order == "") || length < o.batchSize {
 			return nil
 		}
 
+		if o.checkpoint != nil && o.key != nil {
+			if err := o.checkpoint(o.lastKey); err != nil {
+				return err
+			}
+		}
+
 		o.offset += length
 	}
 }
+
+// page returns query and arguments of the next page
+func (o *Reader[T]) page() (string, []any) {
+	args := append([]any{}, o.args...)
+	placeholder := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(o.keys) == 0 {
+		if o.order == "" {
+			return o.query, args
+		}
+
+		query := fmt.Sprintf("%s ORDER BY %s LIMIT %s", o.query, o.order, placeholder(o.batchSize))
+		return fmt.Sprintf("%s OFFSET %s", query, placeholder(o.offset)), args
+	}
+
+	keys := quote(o.keys...)
+	query := fmt.Sprintf("SELECT * FROM (%s) AS gear5_page", o.query)
+	if o.lastKey != nil {
+		placeholders := []string{}
+		for _, value := range o.lastKey {
+			placeholders = append(placeholders, placeholder(value))
+		}
+
+		query = fmt.Sprintf("%s WHERE (%s) > (%s)", query, keys, strings.Join(placeholders, ", "))
+	}
+
+	return fmt.Sprintf("%s ORDER BY %s LIMIT %s", query, keys, placeholder(o.batchSize)), args
+}
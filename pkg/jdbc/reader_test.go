@@ -0,0 +1,226 @@
+package jdbc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRows iterates over ids of a single page
+type fakeRows struct {
+	ids   []int
+	index int
+}
+
+func (r *fakeRows) Next() bool {
+	r.index++
+	return r.index <= len(r.ids)
+}
+
+func (r *fakeRows) Err() error {
+	return nil
+}
+
+func (r *fakeRows) current() int {
+	return r.ids[r.index-1]
+}
+
+// fakeTable serves pages of ids 1..size and records executed queries
+type fakeTable struct {
+	size    int
+	queries []string
+	args    [][]any
+}
+
+func (f *fakeTable) scanExec(ctx context.Context, query string, args ...any) (*fakeRows, error) {
+	f.queries = append(f.queries, query)
+	f.args = append(f.args, args)
+
+	return f.page(0, f.size), nil
+}
+
+func (f *fakeTable) offsetExec(ctx context.Context, query string, args ...any) (*fakeRows, error) {
+	f.queries = append(f.queries, query)
+	f.args = append(f.args, args)
+
+	limit, offset := args[len(args)-2].(int), args[len(args)-1].(int)
+	return f.page(offset, limit), nil
+}
+
+func (f *fakeTable) keysetExec(ctx context.Context, query string, args ...any) (*fakeRows, error) {
+	f.queries = append(f.queries, query)
+	f.args = append(f.args, args)
+
+	after := 0
+	if len(args) == 2 {
+		after = args[0].(int)
+	}
+
+	return f.page(after, args[len(args)-1].(int)), nil
+}
+
+func (f *fakeTable) page(after, limit int) *fakeRows {
+	ids := []int{}
+	for id := after + 1; id <= f.size; id++ {
+		ids = append(ids, id)
+	}
+
+	if limit < len(ids) {
+		ids = ids[:limit]
+	}
+
+	return &fakeRows{ids: ids}
+}
+
+func capture(t *testing.T, reader *Reader[*fakeRows]) []int {
+	t.Helper()
+
+	ids := []int{}
+	err := reader.Capture(func(rows *fakeRows) error {
+		ids = append(ids, rows.current())
+		return nil
+	})
+	require.NoError(t, err)
+
+	return ids
+}
+
+func sequence(n int) []int {
+	ids := []int{}
+	for id := 1; id <= n; id++ {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+func TestReaderOffsetPagination(t *testing.T) {
+	table := &fakeTable{size: 7}
+	reader := NewReader(context.Background(), `SELECT * FROM "public"."users" where "id">$1`, 3, table.offsetExec, 0).
+		WithOffset("tableoid, ctid")
+
+	assert.Equal(t, sequence(7), capture(t, reader))
+	assert.Equal(t, []string{
+		`SELECT * FROM "public"."users" where "id">$1 ORDER BY tableoid, ctid LIMIT $2 OFFSET $3`,
+		`SELECT * FROM "public"."users" where "id">$1 ORDER BY tableoid, ctid LIMIT $2 OFFSET $3`,
+		`SELECT * FROM "public"."users" where "id">$1 ORDER BY tableoid, ctid LIMIT $2 OFFSET $3`,
+	}, table.queries)
+	assert.Equal(t, [][]any{{0, 3, 0}, {0, 3, 3}, {0, 3, 6}}, table.args)
+}
+
+func TestReaderSinglePassWithoutOrder(t *testing.T) {
+	table := &fakeTable{size: 7}
+	reader := NewReader(context.Background(), `SELECT * FROM "public"."users" where "id">$1`, 3, table.scanExec, 0)
+
+	// unordered rows are never paged by LIMIT/OFFSET
+	assert.Equal(t, sequence(7), capture(t, reader))
+	assert.Equal(t, []string{`SELECT * FROM "public"."users" where "id">$1`}, table.queries)
+	assert.Equal(t, [][]any{{0}}, table.args)
+}
+
+func TestReaderKeysetPagination(t *testing.T) {
+	table := &fakeTable{size: 7}
+	reader := NewReader(context.Background(), `SELECT * FROM "public"."users"`, 3, table.keysetExec).
+		WithKeyset([]string{"id"}, func(rows *fakeRows) ([]any, error) {
+			return []any{rows.current()}, nil
+		})
+
+	assert.Equal(t, sequence(7), capture(t, reader))
+	assert.Equal(t, []string{
+		`SELECT * FROM (SELECT * FROM "public"."users") AS gear5_page ORDER BY "id" LIMIT $1`,
+		`SELECT * FROM (SELECT * FROM "public"."users") AS gear5_page WHERE ("id") > ($1) ORDER BY "id" LIMIT $2`,
+		`SELECT * FROM (SELECT * FROM "public"."users") AS gear5_page WHERE ("id") > ($1) ORDER BY "id" LIMIT $2`,
+	}, table.queries)
+	assert.Equal(t, [][]any{{3}, {3, 3}, {6, 3}}, table.args)
+}
+
+func TestReaderResumesFromCheckpoint(t *testing.T) {
+	table := &fakeTable{size: 8}
+	checkpoints := [][]any{}
+	reader := NewReader(context.Background(), `SELECT * FROM "public"."users"`, 3, table.keysetExec).
+		WithKeyset([]string{"id"}, func(rows *fakeRows) ([]any, error) {
+			return []any{rows.current()}, nil
+		}).
+		WithStartKey([]any{2}).
+		WithCheckpoint(func(key []any) error {
+			checkpoints = append(checkpoints, key)
+			return nil
+		})
+
+	assert.Equal(t, []int{3, 4, 5, 6, 7, 8}, capture(t, reader))
+	assert.Equal(t, [][]any{{2, 3}, {5, 3}, {8, 3}}, table.args)
+	// partial last page is not checkpointed
+	assert.Equal(t, [][]any{{5}, {8}}, checkpoints)
+}
+
+func TestReaderStopsOnExactlyFullLastPage(t *testing.T) {
+	table := &fakeTable{size: 6}
+	reader := NewReader(context.Background(), `SELECT * FROM "public"."users"`, 3, table.keysetExec).
+		WithKeyset([]string{"id"}, func(rows *fakeRows) ([]any, error) {
+			return []any{rows.current()}, nil
+		})
+
+	assert.Equal(t, sequence(6), capture(t, reader))
+	// last query returns an empty page
+	assert.Len(t, table.queries, 3)
+}
+
+func TestReaderKeysetPlaceholdersFollowBaseArgs(t *testing.T) {
+	reader := NewReader[*fakeRows](context.Background(), `SELECT * FROM "public"."users" where "updated_at">$1`, 10, nil, "2024-01-01").
+		WithKeyset([]string{"updated_at", "id"}, nil)
+	reader.lastKey = []any{"2024-02-01", 42}
+
+	query, args := reader.page()
+	assert.Equal(t, `SELECT * FROM (SELECT * FROM "public"."users" where "updated_at">$1) AS gear5_page WHERE ("updated_at", "id") > ($2, $3) ORDER BY "updated_at", "id" LIMIT $4`, query)
+	assert.Equal(t, []any{"2024-01-01", "2024-02-01", 42, 10}, args)
+}
+
+func TestReaderErrors(t *testing.T) {
+	table := &fakeTable{size: 1}
+	reader := NewReader(context.Background(), `SELECT * FROM "public"."users";`, 3, table.scanExec)
+	assert.Error(t, reader.Capture(func(*fakeRows) error { return nil }))
+	assert.Empty(t, table.queries)
+
+	failure := errors.New("exec failed")
+	reader = NewReader(context.Background(), `SELECT * FROM "public"."users"`, 3, func(ctx context.Context, query string, args ...any) (*fakeRows, error) {
+		return nil, failure
+	})
+	assert.ErrorIs(t, reader.Capture(func(*fakeRows) error { return nil }), failure)
+
+	table = &fakeTable{size: 5}
+	reader = NewReader(context.Background(), `SELECT * FROM "public"."users"`, 3, table.keysetExec).
+		WithKeyset([]string{"id"}, func(*fakeRows) ([]any, error) {
+			return nil, failure
+		})
+	assert.Error(t, reader.Capture(func(*fakeRows) error { return nil }))
+	assert.Len(t, table.queries, 1)
+}
+
+func TestReaderStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	table := &fakeTable{size: 7}
+	checkpoints := [][]any{}
+	reader := NewReader(ctx, `SELECT * FROM "public"."users"`, 3, table.keysetExec).
+		WithKeyset([]string{"id"}, func(rows *fakeRows) ([]any, error) {
+			return []any{rows.current()}, nil
+		}).
+		WithCheckpoint(func(key []any) error {
+			checkpoints = append(checkpoints, key)
+			cancel()
+			return nil
+		})
+
+	ids := []int{}
+	err := reader.Capture(func(rows *fakeRows) error {
+		ids = append(ids, rows.current())
+		return nil
+	})
+
+	// page checkpointed before cancel is the last one read
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []int{1, 2, 3}, ids)
+	assert.Equal(t, [][]any{{3}}, checkpoints)
+}
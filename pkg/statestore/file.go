@@ -0,0 +1,97 @@
+package statestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/gear5sh/gear5/types"
+)
+
+// FileStore keeps state in a local JSON file; saves replace the file atomically by
+// renaming a synced temporary file and runs are serialized by flock on a lock file
+type FileStore struct {
+	path string
+	lock *os.File
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (f *FileStore) Lock(ctx context.Context) error {
+	file, err := os.OpenFile(f.path+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %s", err)
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return ErrLocked
+		}
+
+		return fmt.Errorf("failed to lock %s: %s", f.path, err)
+	}
+
+	f.lock = file
+	return nil
+}
+
+// Load returns nil if no state has been saved yet
+func (f *FileStore) Load(ctx context.Context) (*types.State, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %s", err)
+	}
+
+	return types.ParseState(data)
+}
+
+func (f *FileStore) Save(ctx context.Context, state *types.State) error {
+	data, err := marshal(state)
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary state file: %s", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write state: %s", err)
+	}
+
+	// state must be durable before it replaces the previous one
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to sync state: %s", err)
+	}
+
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), f.path)
+}
+
+// Close releases the lock
+func (f *FileStore) Close() error {
+	if f.lock == nil {
+		return nil
+	}
+
+	err := f.lock.Close()
+	f.lock = nil
+
+	return err
+}
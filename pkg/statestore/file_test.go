@@ -0,0 +1,59 @@
+package statestore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gear5sh/gear5/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+	store := NewFileStore(path)
+	require.NoError(t, store.Lock(ctx))
+	defer store.Close()
+
+	loaded, err := store.Load(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+
+	state := &types.State{
+		Mutex: &sync.Mutex{},
+		Type:  types.StreamType,
+		Streams: []*types.StreamState{
+			{Stream: "users", Namespace: "public", State: map[string]any{"updated_at": "2024-01-01T00:00:00Z"}},
+		},
+	}
+	require.NoError(t, store.Save(ctx, state))
+	require.NoError(t, store.Save(ctx, state))
+
+	loaded, err = store.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, types.StreamType, loaded.Type)
+	assert.Equal(t, state.Streams, loaded.Streams)
+
+	// temporary files are renamed over the state file
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestFileStoreLock(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+	first := NewFileStore(path)
+	require.NoError(t, first.Lock(ctx))
+
+	second := NewFileStore(path)
+	assert.ErrorIs(t, second.Lock(ctx), ErrLocked)
+
+	require.NoError(t, first.Close())
+	require.NoError(t, second.Lock(ctx))
+	require.NoError(t, second.Close())
+}
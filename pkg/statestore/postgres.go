@@ -0,0 +1,98 @@
+package statestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gear5sh/gear5/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const postgresTable = "gear5_state"
+
+// PostgresStore keeps state of runs by key in a table; runs of a key are serialized by a
+// session level advisory lock held until the store is closed
+type PostgresStore struct {
+	conn postgresConn
+	key  string
+}
+
+// postgresConn is the part of *pgx.Conn used by PostgresStore
+type postgresConn interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Close(ctx context.Context) error
+}
+
+// NewPostgresStore connects to database of connection and creates state table if missing
+func NewPostgresStore(ctx context.Context, connection, key string) (*PostgresStore, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key of state is required with %s state backend", Postgres)
+	}
+
+	conn, err := pgx.Connect(ctx, connection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect state database: %s", err)
+	}
+
+	_, err = conn.Exec(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+key TEXT PRIMARY KEY,
+state JSONB NOT NULL,
+updated_at TIMESTAMPTZ NOT NULL DEFAULT now())`, postgresTable))
+	if err != nil {
+		conn.Close(context.Background())
+		return nil, fmt.Errorf("failed to create state table: %s", err)
+	}
+
+	return &PostgresStore{conn: conn, key: key}, nil
+}
+
+func (p *PostgresStore) Lock(ctx context.Context) error {
+	var locked bool
+	err := p.conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", fmt.Sprintf("%s:%s", postgresTable, p.key)).Scan(&locked)
+	if err != nil {
+		return fmt.Errorf("failed to lock state: %s", err)
+	}
+
+	if !locked {
+		return ErrLocked
+	}
+
+	return nil
+}
+
+// Load returns nil if no state has been saved yet
+func (p *PostgresStore) Load(ctx context.Context) (*types.State, error) {
+	var data []byte
+	err := p.conn.QueryRow(ctx, fmt.Sprintf("SELECT state FROM %s WHERE key = $1", postgresTable), p.key).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %s", err)
+	}
+
+	return types.ParseState(data)
+}
+
+func (p *PostgresStore) Save(ctx context.Context, state *types.State) error {
+	data, err := marshal(state)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.conn.Exec(ctx, fmt.Sprintf(`INSERT INTO %s (key, state) VALUES ($1, $2)
+ON CONFLICT (key) DO UPDATE SET state = EXCLUDED.state, updated_at = now()`, postgresTable), p.key, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to save state: %s", err)
+	}
+
+	return nil
+}
+
+// Close releases the lock with the session
+func (p *PostgresStore) Close() error {
+	return p.conn.Close(context.Background())
+}
@@ -0,0 +1,114 @@
+package statestore
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gear5sh/gear5/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConn serves state table and advisory locks of PostgresStore from memory; locks are
+// held by connection
+type fakeConn struct {
+	states map[string]string
+	locks  map[string]*fakeConn
+}
+
+type fakeRow func(dest ...any) error
+
+func (r fakeRow) Scan(dest ...any) error {
+	return r(dest...)
+}
+
+func (c *fakeConn) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	c.states[arguments[0].(string)] = arguments[1].(string)
+	return pgconn.NewCommandTag("INSERT 0 1"), nil
+}
+
+func (c *fakeConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	key := args[0].(string)
+	if strings.Contains(sql, "pg_try_advisory_lock") {
+		return fakeRow(func(dest ...any) error {
+			holder, found := c.locks[key]
+			if !found {
+				c.locks[key] = c
+			}
+
+			*dest[0].(*bool) = !found || holder == c
+			return nil
+		})
+	}
+
+	return fakeRow(func(dest ...any) error {
+		state, found := c.states[key]
+		if !found {
+			return pgx.ErrNoRows
+		}
+
+		*dest[0].(*[]byte) = []byte(state)
+		return nil
+	})
+}
+
+func (c *fakeConn) Close(ctx context.Context) error {
+	for key, holder := range c.locks {
+		if holder == c {
+			delete(c.locks, key)
+		}
+	}
+
+	return nil
+}
+
+func TestPostgresStore(t *testing.T) {
+	ctx := context.Background()
+	conn := &fakeConn{states: map[string]string{}, locks: map[string]*fakeConn{}}
+	store := &PostgresStore{conn: conn, key: "users"}
+	require.NoError(t, store.Lock(ctx))
+	defer store.Close()
+
+	loaded, err := store.Load(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+
+	state := &types.State{
+		Mutex: &sync.Mutex{},
+		Type:  types.StreamType,
+		Streams: []*types.StreamState{
+			{Stream: "users", Namespace: "public", State: map[string]any{"updated_at": "2024-01-01T00:00:00Z"}},
+		},
+	}
+	require.NoError(t, store.Save(ctx, state))
+	require.NoError(t, store.Save(ctx, state))
+	assert.Len(t, conn.states, 1)
+
+	loaded, err = store.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, types.StreamType, loaded.Type)
+	assert.Equal(t, state.Streams, loaded.Streams)
+}
+
+func TestPostgresStoreLock(t *testing.T) {
+	ctx := context.Background()
+	locks := map[string]*fakeConn{}
+	first := &PostgresStore{conn: &fakeConn{locks: locks}, key: "users"}
+	require.NoError(t, first.Lock(ctx))
+
+	second := &PostgresStore{conn: &fakeConn{locks: locks}, key: "users"}
+	assert.ErrorIs(t, second.Lock(ctx), ErrLocked)
+
+	// stores of other keys aren't serialized
+	other := &PostgresStore{conn: &fakeConn{locks: locks}, key: "orders"}
+	require.NoError(t, other.Lock(ctx))
+
+	require.NoError(t, first.Close())
+	require.NoError(t, second.Lock(ctx))
+	require.NoError(t, second.Close())
+	require.NoError(t, other.Close())
+}
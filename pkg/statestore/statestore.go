@@ -0,0 +1,27 @@
+package statestore
+
+import (
+	"errors"
+
+	"github.com/gear5sh/gear5/types"
+	"github.com/goccy/go-json"
+)
+
+// Supported state backends
+const (
+	File     = "file"
+	Postgres = "postgres"
+)
+
+// ErrLocked is returned by Lock when another run holds the lock of a store
+var ErrLocked = errors.New("state store is locked by another run")
+
+// marshal encodes state while holding its lock against concurrent updates of streams
+func marshal(state *types.State) ([]byte, error) {
+	if state.Mutex != nil {
+		state.Lock()
+		defer state.Unlock()
+	}
+
+	return json.Marshal(state)
+}
@@ -6,67 +6,170 @@ import (
 
 	"github.com/goccy/go-json"
 
+	"github.com/gear5sh/gear5/logger"
 	"github.com/gear5sh/gear5/protocol"
+	"github.com/gear5sh/gear5/typeutils"
 	"github.com/gear5sh/gear5/utils"
 	"github.com/jackc/pglogrepl"
 )
 
+// ChangeFilter decodes wal2json format-version 2 messages; a message per change
+// enclosed by begin and commit messages
 type ChangeFilter struct {
-	tables map[string]protocol.Stream
+	tables     map[string]protocol.Stream
+	partitions map[string]string
+	signal     string         // signal table; inserted rows are emitted as signal changes
+	xid        uint32         // transaction being decoded
+	timestamp  typeutils.Time // commit time of transaction being decoded
 }
 
 type Filtered func(change WalJSChange)
 
-func NewChangeFilter(streams ...protocol.Stream) ChangeFilter {
-	filter := ChangeFilter{
-		tables: make(map[string]protocol.Stream),
+// Filter decodes WAL data of a logical decoding plugin into changes of tracked tables;
+// transaction boundaries are reported as begin and commit changes without stream and
+// rows inserted into the signal table as signal changes
+type Filter interface {
+	FilterChange(lsn pglogrepl.LSN, change []byte, OnFiltered Filtered) error
+}
+
+var wal2jsonKinds = map[string]string{
+	"I": "insert",
+	"U": "update",
+	"D": "delete",
+	"T": "truncate",
+}
+
+func NewChangeFilter(signal string, partitions map[string]string, streams ...protocol.Stream) *ChangeFilter {
+	return &ChangeFilter{
+		tables:     tracked(partitions, streams...),
+		partitions: partitions,
+		signal:     signal,
 	}
+}
 
+// tracked maps tables to their streams; partitions are mapped to the stream of their root table
+func tracked(partitions map[string]string, streams ...protocol.Stream) map[string]protocol.Stream {
+	tables := make(map[string]protocol.Stream)
 	for _, stream := range streams {
-		filter.tables[stream.ID()] = stream
+		tables[stream.ID()] = stream
+	}
+
+	for partition, root := range partitions {
+		if stream, found := tables[root]; found {
+			tables[partition] = stream
+		}
+	}
+
+	return tables
+}
+
+// partitionTruncated reports truncates of a single partition; these are skipped since
+// destination holds rows of all partitions in the table of the root
+func partitionTruncated(partitions map[string]string, id, kind string) bool {
+	if _, found := partitions[id]; !found || kind != "truncate" {
+		return false
 	}
 
-	return filter
+	logger.Warnf("Skipping truncate of partition %s; truncated rows are not removed from destination", id)
+	return true
 }
 
-func (c ChangeFilter) FilterChange(lsn pglogrepl.LSN, change []byte, OnFiltered Filtered) error {
-	var changes WALMessage
-	if err := json.NewDecoder(bytes.NewReader(change)).Decode(&changes); err != nil {
+func (c *ChangeFilter) FilterChange(lsn pglogrepl.LSN, change []byte, OnFiltered Filtered) error {
+	var message WALMessage
+	if err := json.NewDecoder(bytes.NewReader(change)).Decode(&message); err != nil {
 		return fmt.Errorf("cant parse change from database to filter it: %s", err)
 	}
 
-	if len(changes.Change) == 0 {
+	switch message.Action {
+	case "B":
+		c.xid = message.Xid
+		c.timestamp = message.Timestamp
+		OnFiltered(WalJSChange{Kind: "begin", Xid: c.xid, LSN: &lsn})
+		return nil
+	case "C":
+		OnFiltered(WalJSChange{Kind: "commit", Xid: c.xid, LSN: &lsn})
 		return nil
 	}
 
-	for _, ch := range changes.Change {
-		stream, exists := c.tables[utils.StreamIdentifier(ch.Table, ch.Schema)]
-		if !exists {
-			continue
-		}
+	// logical decoding messages carry no rows
+	kind, found := wal2jsonKinds[message.Action]
+	if !found {
+		return nil
+	}
 
-		// builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
-		changesMap := map[string]any{}
-		if ch.Kind == "delete" {
-			for i, changedValue := range ch.Oldkeys.Keyvalues {
-				changesMap[ch.Oldkeys.Keynames[i]] = changedValue
-			}
-		} else {
-			for i, changedValue := range ch.Columnvalues {
-				changesMap[ch.Columnnames[i]] = changedValue
+	id := utils.StreamIdentifier(message.Table, message.Schema)
+	if c.signal != "" && id == c.signal {
+		if kind == "insert" {
+			signal := map[string]any{}
+			for _, column := range message.Columns {
+				signal[column.Name] = column.Value
 			}
+
+			OnFiltered(WalJSChange{Kind: "signal", LSN: &lsn, Xid: c.xid, Data: signal})
 		}
 
-		OnFiltered(WalJSChange{
-			Stream:    stream,
-			Kind:      ch.Kind,
-			Schema:    ch.Schema,
-			Table:     ch.Table,
-			Timestamp: &changes.Timestamp,
-			LSN:       &lsn,
-			Data:      changesMap,
-		})
+		return nil
 	}
 
+	stream, exists := c.tables[id]
+	if !exists || partitionTruncated(c.partitions, id, kind) {
+		return nil
+	}
+
+	changesMap := map[string]any{}
+	var before map[string]any
+	if len(message.Identity) > 0 {
+		// identity carries the full row with REPLICA IDENTITY FULL
+		before = map[string]any{}
+		for _, column := range message.Identity {
+			before[column.Name] = column.Value
+		}
+	}
+
+	if kind == "delete" {
+		for column, value := range before {
+			changesMap[column] = value
+		}
+	}
+
+	var columnTypes map[string]string
+	if kind == "insert" || kind == "update" {
+		columnTypes = map[string]string{}
+		for _, column := range message.Columns {
+			changesMap[column.Name] = column.Value
+			if column.Type != "" {
+				columnTypes[column.Name] = column.Type
+			}
+		}
+	}
+
+	timestamp := c.timestamp
+	OnFiltered(WalJSChange{
+		Stream:    stream,
+		Kind:      kind,
+		Schema:    message.Schema,
+		Table:     message.Table,
+		Timestamp: &timestamp,
+		LSN:       &lsn,
+		Xid:       c.xid,
+		Data:      changesMap,
+		Before:    before,
+
+		ColumnTypes: columnTypes,
+	})
+
 	return nil
 }
+
+// exclude removes columns excluded from stream of change
+func exclude(change WalJSChange) {
+	if change.Stream == nil {
+		return
+	}
+
+	for _, column := range change.Stream.Self().ExcludeColumns {
+		delete(change.Data, column)
+		delete(change.Before, column)
+		delete(change.ColumnTypes, column)
+	}
+}
@@ -0,0 +1,276 @@
+package waljs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gear5sh/gear5/logger"
+	"github.com/gear5sh/gear5/pkg/jdbc"
+	"github.com/gear5sh/gear5/protocol"
+	"github.com/gear5sh/gear5/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// state key for progress of an incremental snapshot
+const SnapshotStateKey = "incremental_snapshot"
+
+// watermark types written to the signal table
+const (
+	lowWatermark  = "low"
+	highWatermark = "high"
+)
+
+type snapshotProgress struct {
+	After []string `json:"after"` // primary key of the last emitted row
+}
+
+// window is a chunk of an incremental snapshot read between a low and a high watermark;
+// rows changed while the window is open are dropped since the log holds a newer version
+type window struct {
+	id     string
+	stream protocol.Stream
+	keys   []string
+	rows   map[string]map[string]any
+	order  []string      // keys of rows in primary key order
+	last   []string      // primary key of the last row
+	final  bool          // chunk is the last of the table
+	open   bool          // low watermark has been received
+	loaded chan struct{} // closed once the chunk has been read
+	done   chan struct{} // closed once the chunk has been emitted
+}
+
+func (s *Socket) currentWindow() *window {
+	s.windowMu.Lock()
+	defer s.windowMu.Unlock()
+
+	return s.window
+}
+
+func (s *Socket) setWindow(w *window) {
+	s.windowMu.Lock()
+	defer s.windowMu.Unlock()
+
+	s.window = w
+}
+
+// incrementalSnapshot reads streams chunk by chunk while changes are streamed
+func (s *Socket) incrementalSnapshot(streams []protocol.Stream) {
+	defer s.snapshotting.Store(false)
+
+	for _, stream := range streams {
+		if err := s.snapshotStream(stream); err != nil {
+			// progress of an interrupted snapshot is resumed by the next run
+			if s.ctx.Err() == nil {
+				s.err <- fmt.Errorf("incremental snapshot of %s failed: %s", stream.ID(), err)
+			}
+			return
+		}
+	}
+}
+
+func (s *Socket) snapshotStream(stream protocol.Stream) error {
+	keys := jdbc.FullRefreshKeys(stream)
+
+	var after []string
+	if saved := stream.GetStateKey(SnapshotStateKey); saved != nil && !s.recovery {
+		progress := &snapshotProgress{}
+		if err := utils.Unmarshal(saved, progress); err != nil {
+			return fmt.Errorf("failed to parse snapshot state: %s", err)
+		}
+
+		if len(progress.After) == len(keys) {
+			logger.Infof("Resuming incremental snapshot of %s after %v", stream.ID(), progress.After)
+			after = progress.After
+		}
+	}
+
+	logger.Infof("Processing incremental snapshot: %s", stream.ID())
+
+	for chunk := 0; ; chunk++ {
+		w := &window{
+			id:     fmt.Sprintf("%s:%d:%d", stream.ID(), time.Now().UnixNano(), chunk),
+			stream: stream,
+			keys:   keys,
+			rows:   make(map[string]map[string]any),
+			last:   after,
+			loaded: make(chan struct{}),
+			done:   make(chan struct{}),
+		}
+
+		s.setWindow(w)
+		if err := s.watermark(w.id, lowWatermark); err != nil {
+			return err
+		}
+
+		err := s.readChunk(w, after)
+		close(w.loaded)
+		if err != nil {
+			return fmt.Errorf("failed to read chunk: %s", err)
+		}
+
+		if err := s.watermark(w.id, highWatermark); err != nil {
+			return err
+		}
+
+		select {
+		case <-w.done:
+		case <-s.Done:
+			return nil
+		case <-s.ctx.Done():
+			return nil
+		}
+
+		if _, err := s.pgxConn.Exec(s.ctx, fmt.Sprintf("DELETE FROM %s WHERE data = $1", s.signalTable()), w.id); err != nil {
+			return fmt.Errorf("failed to delete watermarks: %s", err)
+		}
+
+		if w.final {
+			logger.Infof("Completed incremental snapshot of %s", stream.ID())
+			return nil
+		}
+
+		after = w.last
+	}
+}
+
+// watermark inserts a watermark of window into the signal table
+func (s *Socket) watermark(id, kind string) error {
+	_, err := s.pgxConn.Exec(s.ctx, fmt.Sprintf("INSERT INTO %s (id, type, data) VALUES ($1, $2, $3)", s.signalTable()),
+		fmt.Sprintf("%s:%s", id, kind), kind, id)
+	if err != nil {
+		return fmt.Errorf("failed to write %s watermark: %s", kind, err)
+	}
+
+	return nil
+}
+
+func (s *Socket) readChunk(w *window, after []string) error {
+	query, args, err := jdbc.PostgresKeyChunk(w.stream, after, s.ChunkSize)
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.pgxConn.Query(s.ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return err
+		}
+
+		data := map[string]any{}
+		columns := rows.FieldDescriptions()
+		for i, v := range values {
+			data[columns[i].Name] = v
+		}
+
+		key, found := keyOf(w.keys, data)
+		if !found {
+			return fmt.Errorf("primary key missing from row")
+		}
+
+		id := strings.Join(key, "\x00")
+		w.rows[id] = data
+		w.order = append(w.order, id)
+		w.last = key
+	}
+
+	w.final = len(w.order) < s.ChunkSize
+
+	return rows.Err()
+}
+
+// onSignal handles watermarks of the current window; remaining rows of the chunk are
+// emitted at the high watermark followed by a checkpoint of snapshot progress
+func (s *Socket) onSignal(change WalJSChange) {
+	w := s.currentWindow()
+	if w == nil || change.Data["data"] != w.id {
+		return
+	}
+
+	switch change.Data["type"] {
+	case lowWatermark:
+		<-w.loaded
+		w.open = true
+	case highWatermark:
+		if !w.open {
+			return
+		}
+
+		for _, id := range w.order {
+			row, found := w.rows[id]
+			if !found {
+				continue
+			}
+
+			s.messages <- WalJSChange{
+				Stream: w.stream,
+				Kind:   "insert",
+				Schema: w.stream.Namespace(),
+				Table:  w.stream.Name(),
+				Data:   row,
+			}
+		}
+
+		stream, last, final := w.stream, w.last, w.final
+		s.messages <- WalJSChange{
+			Kind: "checkpoint",
+			Checkpoint: func() {
+				if final {
+					stream.SetStateKey(SnapshotStateKey, nil)
+					return
+				}
+
+				stream.SetStateKey(SnapshotStateKey, snapshotProgress{After: last})
+			},
+		}
+
+		s.setWindow(nil)
+		close(w.done)
+	}
+}
+
+// dropFromWindow removes rows of the open window changed by change
+func (s *Socket) dropFromWindow(change WalJSChange) {
+	w := s.currentWindow()
+	if w == nil || !w.open || change.Stream != w.stream {
+		return
+	}
+
+	if change.Kind == "truncate" {
+		w.rows = make(map[string]map[string]any)
+		return
+	}
+
+	// primary key may have been updated
+	for _, data := range []map[string]any{change.Data, change.Before} {
+		if key, found := keyOf(w.keys, data); found {
+			delete(w.rows, strings.Join(key, "\x00"))
+		}
+	}
+}
+
+func (s *Socket) signalTable() string {
+	schema, table, _ := strings.Cut(s.SignalTable, ".")
+	return pgx.Identifier{schema, table}.Sanitize()
+}
+
+// keyOf returns values of key columns of data formatted by jdbc.KeyString
+func keyOf(columns []string, data map[string]any) ([]string, bool) {
+	key := []string{}
+	for _, column := range columns {
+		value, found := data[column]
+		if !found || value == nil {
+			return nil, false
+		}
+
+		key = append(key, jdbc.KeyString(value))
+	}
+
+	return key, true
+}
@@ -0,0 +1,189 @@
+package waljs
+
+import (
+	"fmt"
+
+	"github.com/gear5sh/gear5/logger"
+	"github.com/gear5sh/gear5/protocol"
+	"github.com/gear5sh/gear5/typeutils"
+	"github.com/gear5sh/gear5/utils"
+	"github.com/jackc/pglogrepl"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PgOutputFilter decodes messages of the built-in pgoutput plugin
+type PgOutputFilter struct {
+	tables     map[string]protocol.Stream
+	partitions map[string]string
+	signal     string // signal table; inserted rows are emitted as signal changes
+	relations  map[uint32]*pglogrepl.RelationMessage
+	typeMap    *pgtype.Map
+	xid        uint32         // transaction being decoded
+	timestamp  typeutils.Time // commit time of the transaction being decoded
+}
+
+// NewPgOutputFilter decodes changes of streams; changes of partitions are decoded as
+// changes of their root table unless published through the root
+func NewPgOutputFilter(signal string, partitions map[string]string, streams ...protocol.Stream) *PgOutputFilter {
+	return &PgOutputFilter{
+		tables:     tracked(partitions, streams...),
+		partitions: partitions,
+		signal:     signal,
+		relations:  make(map[uint32]*pglogrepl.RelationMessage),
+		typeMap:    pgtype.NewMap(),
+	}
+}
+
+func (p *PgOutputFilter) FilterChange(lsn pglogrepl.LSN, change []byte, OnFiltered Filtered) error {
+	message, err := pglogrepl.Parse(change)
+	if err != nil {
+		return fmt.Errorf("failed to parse pgoutput message: %s", err)
+	}
+
+	switch message := message.(type) {
+	case *pglogrepl.RelationMessage:
+		// relations are sent before their first change in a session and on every schema change
+		p.relations[message.RelationID] = message
+	case *pglogrepl.BeginMessage:
+		p.xid = message.Xid
+		p.timestamp = typeutils.Time{Time: message.CommitTime}
+		OnFiltered(WalJSChange{Kind: "begin", Xid: p.xid, LSN: &lsn})
+	case *pglogrepl.CommitMessage:
+		end := message.TransactionEndLSN
+		OnFiltered(WalJSChange{Kind: "commit", Xid: p.xid, LSN: &end})
+	case *pglogrepl.InsertMessage:
+		return p.emit(lsn, "insert", message.RelationID, message.Tuple, nil, 0, OnFiltered)
+	case *pglogrepl.UpdateMessage:
+		return p.emit(lsn, "update", message.RelationID, message.NewTuple, message.OldTuple, message.OldTupleType, OnFiltered)
+	case *pglogrepl.DeleteMessage:
+		return p.emit(lsn, "delete", message.RelationID, message.OldTuple, message.OldTuple, message.OldTupleType, OnFiltered)
+	case *pglogrepl.TruncateMessage:
+		for _, relationID := range message.RelationIDs {
+			if err := p.emit(lsn, "truncate", relationID, nil, nil, 0, OnFiltered); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// emit decodes tuples of a relation into a change; old tuple holds only key columns
+// unless replica identity is FULL
+func (p *PgOutputFilter) emit(lsn pglogrepl.LSN, kind string, relationID uint32, tuple, old *pglogrepl.TupleData, oldType uint8, OnFiltered Filtered) error {
+	relation, found := p.relations[relationID]
+	if !found {
+		return fmt.Errorf("unknown relation id %d", relationID)
+	}
+
+	id := utils.StreamIdentifier(relation.RelationName, relation.Namespace)
+	if p.signal != "" && id == p.signal {
+		if kind != "insert" {
+			return nil
+		}
+
+		signal, err := p.decodeTuple(id, relation, tuple, false)
+		if err != nil {
+			return err
+		}
+
+		OnFiltered(WalJSChange{Kind: "signal", LSN: &lsn, Xid: p.xid, Data: signal})
+		return nil
+	}
+
+	stream, exists := p.tables[id]
+	if !exists || partitionTruncated(p.partitions, id, kind) {
+		return nil
+	}
+
+	keysOnly := oldType == pglogrepl.UpdateMessageTupleTypeKey
+	data, err := p.decodeTuple(id, relation, tuple, kind == "delete" && keysOnly)
+	if err != nil {
+		return err
+	}
+
+	var before map[string]any
+	if old != nil {
+		before, err = p.decodeTuple(id, relation, old, keysOnly)
+		if err != nil {
+			return err
+		}
+	}
+
+	var columnTypes map[string]string
+	if kind == "insert" || kind == "update" {
+		columnTypes = map[string]string{}
+		for _, column := range relation.Columns {
+			if typ, found := p.typeMap.TypeForOID(column.DataType); found {
+				columnTypes[column.Name] = typ.Name
+			}
+		}
+	}
+
+	timestamp := p.timestamp
+	OnFiltered(WalJSChange{
+		Stream:    stream,
+		Kind:      kind,
+		Schema:    relation.Namespace,
+		Table:     relation.RelationName,
+		Timestamp: &timestamp,
+		LSN:       &lsn,
+		Xid:       p.xid,
+		Data:      data,
+		Before:    before,
+
+		ColumnTypes: columnTypes,
+	})
+
+	return nil
+}
+
+// decodeTuple decodes columns of tuple; only key columns are kept if keysOnly
+func (p *PgOutputFilter) decodeTuple(id string, relation *pglogrepl.RelationMessage, tuple *pglogrepl.TupleData, keysOnly bool) (map[string]any, error) {
+	data := map[string]any{}
+	if tuple == nil {
+		return data, nil
+	}
+
+	for i, column := range tuple.Columns {
+		if i >= len(relation.Columns) {
+			return nil, fmt.Errorf("tuple of %s has more columns than relation", id)
+		}
+
+		meta := relation.Columns[i]
+		if keysOnly && meta.Flags&1 == 0 {
+			continue
+		}
+
+		switch column.DataType {
+		case pglogrepl.TupleDataTypeNull:
+			data[meta.Name] = nil
+		case pglogrepl.TupleDataTypeToast:
+			// unchanged toasted values are not sent
+			logger.Debugf("skipping unchanged toasted column %s of %s", meta.Name, id)
+		case pglogrepl.TupleDataTypeText, pglogrepl.TupleDataTypeBinary:
+			value, err := p.decode(column, meta.DataType)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode column %s of %s: %s", meta.Name, id, err)
+			}
+
+			data[meta.Name] = value
+		}
+	}
+
+	return data, nil
+}
+
+func (p *PgOutputFilter) decode(column *pglogrepl.TupleDataColumn, oid uint32) (any, error) {
+	format := int16(pgtype.TextFormatCode)
+	if column.DataType == pglogrepl.TupleDataTypeBinary {
+		format = pgtype.BinaryFormatCode
+	}
+
+	if typ, found := p.typeMap.TypeForOID(oid); found {
+		return typ.Codec.DecodeValue(p.typeMap, oid, format, column.Data)
+	}
+
+	// user defined types e.g. enums are kept as text
+	return string(column.Data), nil
+}
@@ -2,14 +2,18 @@ package waljs
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/gear5sh/gear5/protocol"
+	"github.com/gear5sh/gear5/utils"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/lib/pq"
 )
 
 type Snapshotter struct {
+	conn   *pgx.Conn // owned by snapshotter; the connection of watermarks stays open
 	tx     pgx.Tx
 	stream protocol.Stream
 }
@@ -20,8 +24,15 @@ func NewSnapshotter(stream protocol.Stream, batchSize int) *Snapshotter {
 	}
 }
 
-func (s *Snapshotter) Prepare(conn *pgx.Conn) error {
-	tx, err := conn.BeginTx(context.TODO(), pgx.TxOptions{
+// Prepare opens a connection of dsn and begins the snapshot transaction on it
+func (s *Snapshotter) Prepare(ctx context.Context, dsn string) error {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel: pgx.RepeatableRead,
 	})
 
@@ -31,13 +42,41 @@ func (s *Snapshotter) Prepare(conn *pgx.Conn) error {
 }
 
 func (s *Snapshotter) ReleaseSnapshot() error {
+	if s.tx == nil {
+		return nil
+	}
+
 	return s.tx.Commit(context.Background())
 }
 
 func (s *Snapshotter) CloseConn() error {
-	if s.tx != nil {
-		return s.tx.Conn().Close(context.Background())
+	if s.conn != nil {
+		return s.conn.Close(context.Background())
 	}
 
 	return nil
 }
+
+// rowKey returns values of columns from current row of rows
+func rowKey(columns []string) func(rows pgx.Rows) ([]any, error) {
+	return func(rows pgx.Rows) ([]any, error) {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, err
+		}
+
+		key := []any{}
+		for _, column := range columns {
+			i, found := utils.ArrayContains(rows.FieldDescriptions(), func(field pgconn.FieldDescription) bool {
+				return field.Name == column
+			})
+			if !found {
+				return nil, fmt.Errorf("key column %s missing from rows", column)
+			}
+
+			key = append(key, values[i])
+		}
+
+		return key, nil
+	}
+}
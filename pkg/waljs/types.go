@@ -16,10 +16,24 @@ type Config struct {
 	FullSyncTables             *types.Set[protocol.Stream] // full sync tables must be a subset of ChangeTables
 	Connection                 url.URL
 	ReplicationSlotName        string
+	Plugin                     string // logical decoding plugin of replication slot
+	Publication                string // publication streamed by pgoutput
 	InitialWaitTime            time.Duration
 	SnapshotMemorySafetyFactor float64
 	TLSConfig                  *tls.Config
 	State                      *types.Global[*WALState]
+	// Done enables continuous streaming; changes are streamed until it is closed
+	Done <-chan struct{}
+	// Interval of standby status updates while streaming continuously
+	StandbyInterval time.Duration
+	// SignalTable enables incremental snapshots; FullSyncTables are read in chunks
+	// delimited by watermarks written to it while changes are streamed
+	SignalTable string // schema.table
+	// Number of rows read per chunk of an incremental snapshot
+	ChunkSize int
+	// Partitions maps partitions i.e. schema.table to their root table; changes of
+	// partitions are emitted on the stream of the root
+	Partitions map[string]string
 }
 
 type WALState struct {
@@ -40,28 +54,34 @@ type WalJSChange struct {
 	Stream    protocol.Stream
 	Timestamp *typeutils.Time
 	LSN       *pglogrepl.LSN
+	Xid       uint32 // transaction of the change
 	Kind      string
 	Schema    string
 	Table     string
 	Data      map[string]any
+	Before    map[string]any // previous row of updates and deletes as allowed by replica identity
+	// Postgres types of columns in Data; set for inserts and updates
+	ColumnTypes map[string]string
+	// Checkpoint is set on checkpoint changes; it must be called once every change
+	// before it has been emitted
+	Checkpoint func()
 }
 
+// WALMessage is a wal2json format-version 2 message
 type WALMessage struct {
-	// NextLSN   pglogrepl.LSN `json:"nextlsn"`
-	Timestamp typeutils.Time `json:"timestamp"`
-	Change    []struct {
-		Kind         string        `json:"kind"`
-		Schema       string        `json:"schema"`
-		Table        string        `json:"table"`
-		Columnnames  []string      `json:"columnnames"`
-		Columntypes  []string      `json:"columntypes"`
-		Columnvalues []interface{} `json:"columnvalues"`
-		Oldkeys      struct {
-			Keynames  []string      `json:"keynames"`
-			Keytypes  []string      `json:"keytypes"`
-			Keyvalues []interface{} `json:"keyvalues"`
-		} `json:"oldkeys"`
-	} `json:"change"`
+	Action    string         `json:"action"`
+	Xid       uint32         `json:"xid"`
+	Timestamp typeutils.Time `json:"timestamp"` // set on begin
+	Schema    string         `json:"schema"`
+	Table     string         `json:"table"`
+	Columns   []WALColumn    `json:"columns"`
+	Identity  []WALColumn    `json:"identity"`
+}
+
+type WALColumn struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
 }
 
 type OnMessage = func(message WalJSChange) (bool, error)
@@ -5,6 +5,9 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gear5sh/gear5/logger"
@@ -19,38 +22,59 @@ import (
 
 const (
 	ReplicationSlotTempl = "SELECT plugin, slot_type, confirmed_flush_lsn FROM pg_replication_slots WHERE slot_name = '%s'"
+	// closeTimeout bounds closing connections once streaming stops
+	closeTimeout = 10 * time.Second
+)
+
+// Supported logical decoding plugins
+const (
+	Wal2JSON = "wal2json"
+	PgOutput = "pgoutput"
 )
 
 var pluginArguments = []string{
+	"\"format-version\" '2'",
+	"\"include-xids\" 'on'",
 	"\"include-lsn\" 'on'",
-	"\"pretty-print\" 'off'",
 	"\"include-timestamp\" 'on'",
 }
 
 type Socket struct {
 	*Config
 	pgConn  *pgconn.PgConn
-	pgxConn *pgx.Conn
+	pgxConn *pgx.Conn // writes watermarks and reads chunks of incremental snapshots
+	dsn     string    // connection string of connections other than replication
+	ctx     context.Context // streaming stops gracefully once done
 
 	waiter *time.Timer
 	// ctx                        context.Context // Context to use Inital Wait Time
 	// cancel                     context.CancelFunc
 	clientXLogPos              pglogrepl.LSN
+	confirmedLSN               atomic.Uint64 // position whose changes have been emitted
+	inTransaction              bool          // changes of an uncommitted transaction are being received
 	standbyMessageTimeout      time.Duration
 	nextStandbyMessageDeadline time.Time
 	messages                   chan WalJSChange
 	err                        chan error
-	changeFilter               ChangeFilter
+	changeFilter               Filter
 	lsnrestart                 pglogrepl.LSN
 	recovery                   bool
+
+	// chunk of incremental snapshot being read
+	windowMu     sync.Mutex
+	window       *window
+	snapshotting atomic.Bool // incremental snapshot is in progress
 }
 
-func NewConnection(db *sqlx.DB, config *Config) (*Socket, error) {
+// NewConnection connects to postgres for streaming changes; once ctx is done streaming
+// stops after confirming changes already received
+func NewConnection(ctx context.Context, db *sqlx.DB, config *Config) (*Socket, error) {
 	if !config.FullSyncTables.SubsetOf(config.Tables) {
 		return nil, fmt.Errorf("mismatch: full sync tables are not subset of all tables")
 	}
 
-	conn, err := pgx.Connect(context.Background(), config.Connection.String())
+	dsn := config.Connection.String()
+	conn, err := pgx.Connect(ctx, dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +94,7 @@ func NewConnection(db *sqlx.DB, config *Config) (*Socket, error) {
 		}
 	}
 
-	dbConn, err := pgconn.ConnectConfig(context.Background(), cfg)
+	dbConn, err := pgconn.ConnectConfig(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -80,12 +104,30 @@ func NewConnection(db *sqlx.DB, config *Config) (*Socket, error) {
 		standbyMessageTimeout: time.Second,
 		pgConn:                dbConn,
 		pgxConn:               conn,
+		dsn:                   dsn,
+		ctx:                   ctx,
 		messages:              make(chan WalJSChange),
 		err:                   make(chan error),
-		changeFilter:          NewChangeFilter(config.Tables.Array()...),
 	}
 
-	sysident, err := pglogrepl.IdentifySystem(context.Background(), connection.pgConn)
+	switch config.Plugin {
+	case Wal2JSON:
+		connection.changeFilter = NewChangeFilter(config.SignalTable, config.Partitions, config.Tables.Array()...)
+	case PgOutput:
+		if config.Publication == "" {
+			return nil, fmt.Errorf("publication is required with %s", PgOutput)
+		}
+
+		connection.changeFilter = NewPgOutputFilter(config.SignalTable, config.Partitions, config.Tables.Array()...)
+	default:
+		return nil, fmt.Errorf("unsupported plugin: %s", config.Plugin)
+	}
+
+	if config.SignalTable != "" && !strings.Contains(config.SignalTable, ".") {
+		return nil, fmt.Errorf("signal table must be qualified by schema: %s", config.SignalTable)
+	}
+
+	sysident, err := pglogrepl.IdentifySystem(ctx, connection.pgConn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to identify the system: %s", err)
 	}
@@ -93,38 +135,57 @@ func NewConnection(db *sqlx.DB, config *Config) (*Socket, error) {
 	logger.Info("System identification result", "SystemID:", sysident.SystemID, "Timeline:", sysident.Timeline, "XLogPos:", sysident.XLogPos, "Database:", sysident.DBName)
 
 	slot := ReplicationSlot{}
-	err = db.Get(&slot, fmt.Sprintf(ReplicationSlotTempl, config.ReplicationSlotName))
+	err = db.GetContext(ctx, &slot, fmt.Sprintf(ReplicationSlotTempl, config.ReplicationSlotName))
 	if err != nil {
 		return nil, err
 	}
 
+	if slot.Plugin != config.Plugin {
+		return nil, fmt.Errorf("replication slot %s uses plugin %s; configured %s", config.ReplicationSlotName, slot.Plugin, config.Plugin)
+	}
+
+	start := slot.LSN
 	if config.State.State.LSN != "" {
 		stateLSN, err := pglogrepl.ParseLSN(config.State.State.LSN)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse State LSN: %s", err)
 		}
 
-		// difference in confirmed flush lsn from State and DB
-		if stateLSN.String() != slot.LSN.String() {
+		switch {
+		// changes confirmed to slot are missing from State and can't be streamed again
+		case stateLSN < slot.LSN:
 			connection.recovery = true
 			logger.Info("Enabling Recovery mode...")
-			logger.Infof("Reason: Found difference in LSN present in database[%s] and Global State[%s]", slot.LSN.String(), stateLSN.String())
+			logger.Infof("Reason: Global State LSN[%s] is behind confirmed flush LSN of database[%s]", stateLSN.String(), slot.LSN.String())
 
 			// adding all tables in full load for recovery
 			config.Tables.Range(func(s protocol.Stream) {
 				config.FullSyncTables.Insert(s)
 			})
+		// State e.g. rewound ahead of slot; changes are streamed from it
+		case stateLSN > slot.LSN:
+			logger.Infof("Streaming from Global State LSN[%s] ahead of confirmed flush LSN of database[%s]", stateLSN.String(), slot.LSN.String())
+			start = stateLSN
 		}
 	}
 
-	connection.lsnrestart = slot.LSN
-	connection.clientXLogPos = slot.LSN
+	connection.lsnrestart = start
+	connection.clientXLogPos = start
+	connection.confirmedLSN.Store(uint64(start))
+
+	if config.StandbyInterval <= 0 {
+		config.StandbyInterval = 10 * time.Second
+	}
+
+	if config.ChunkSize <= 0 {
+		config.ChunkSize = 10000
+	}
 
 	return connection, err
 }
 
 func (s *Socket) startLr() error {
-	err := pglogrepl.StartReplication(context.Background(), s.pgConn, s.ReplicationSlotName, s.lsnrestart, pglogrepl.StartReplicationOptions{PluginArgs: pluginArguments})
+	err := pglogrepl.StartReplication(s.ctx, s.pgConn, s.ReplicationSlotName, s.lsnrestart, pglogrepl.StartReplicationOptions{PluginArgs: s.pluginArguments()})
 	if err != nil {
 		return fmt.Errorf("starting replication slot failed: %s", err)
 	}
@@ -133,8 +194,8 @@ func (s *Socket) startLr() error {
 	// Setup initial wait timeout to be the next message deadline to wait for a change log
 	s.nextStandbyMessageDeadline = time.Now().Add(s.InitialWaitTime + 2*time.Second)
 
-	// Initial timer only works after one sync is completed
-	if !s.State.State.IsEmpty() {
+	// Initial timer only works after one sync is completed; not used while following
+	if !s.State.State.IsEmpty() && s.Done == nil {
 		logger.Debugf("Setting initial wait timer: %s", s.InitialWaitTime)
 		s.waiter = time.AfterFunc(s.InitialWaitTime, func() {
 			logger.Info("Closing sync. initial wait timer expired...")
@@ -145,8 +206,66 @@ func (s *Socket) startLr() error {
 	return nil
 }
 
+func (s *Socket) pluginArguments() []string {
+	if s.Plugin == PgOutput {
+		return []string{
+			"proto_version '1'",
+			fmt.Sprintf("publication_names '%s'", strings.ReplaceAll(s.Publication, "'", "''")),
+		}
+	}
+
+	// only selected tables and their partitions are decoded
+	tables := []string{}
+	selected := make(map[string]bool)
+	for _, stream := range s.Tables.Array() {
+		tables = append(tables, escapeTable(stream.Namespace(), stream.Name()))
+		selected[stream.ID()] = true
+	}
+
+	for partition, root := range s.Partitions {
+		if selected[root] {
+			schema, table, _ := strings.Cut(partition, ".")
+			tables = append(tables, escapeTable(schema, table))
+		}
+	}
+
+	if s.SignalTable != "" {
+		schema, table, _ := strings.Cut(s.SignalTable, ".")
+		tables = append(tables, escapeTable(schema, table))
+	}
+
+	return append(append([]string{}, pluginArguments...), fmt.Sprintf("\"add-tables\" '%s'", strings.ReplaceAll(strings.Join(tables, ","), "'", "''")))
+}
+
+// escapeTable formats a table for wal2json table lists where separators and wildcards in
+// names are escaped with backslash
+func escapeTable(schema, table string) string {
+	escape := strings.NewReplacer("\\", "\\\\", ",", "\\,", ".", "\\.", "*", "\\*", " ", "\\ ")
+	return fmt.Sprintf("%s.%s", escape.Replace(schema), escape.Replace(table))
+}
+
 // Confirm that Logs has been recorded
 func (s *Socket) AcknowledgeLSN(lsn pglogrepl.LSN) error {
+	s.confirm(lsn)
+
+	return s.sendStandbyStatus()
+}
+
+// confirm updates local pointer and state with a position whose changes have been emitted
+func (s *Socket) confirm(lsn pglogrepl.LSN) {
+	s.confirmedLSN.Store(uint64(lsn))
+	s.Config.State.State.LSN = lsn.String()
+
+	// after acknowledgement attach all streams to Global state
+	for _, stream := range s.Tables.Array() {
+		s.State.Streams.Insert(stream.ID())
+	}
+}
+
+// sendStandbyStatus reports confirmed position to postgres; WAL before it can be recycled.
+// Position is reported even once ctx is done
+func (s *Socket) sendStandbyStatus() error {
+	lsn := pglogrepl.LSN(s.confirmedLSN.Load())
 	err := pglogrepl.SendStandbyStatusUpdate(context.Background(), s.pgConn, pglogrepl.StandbyStatusUpdate{
 		WALWritePosition: lsn,
 		WALFlushPosition: lsn,
@@ -155,14 +274,7 @@ func (s *Socket) AcknowledgeLSN(lsn pglogrepl.LSN) error {
 		return fmt.Errorf("SendStandbyStatusUpdate failed: %s", err)
 	}
 
-	// Update local pointer and state
 	s.clientXLogPos = lsn
-	s.Config.State.State.LSN = lsn.String()
-
-	// after acknowledgement attach all streams to Global state
-	for _, stream := range s.Tables.Array() {
-		s.State.Streams.Insert(stream.ID())
-	}
 
 	logger.Debugf("Sent Standby status message at LSN#%s", s.clientXLogPos.String())
 	return nil
@@ -179,6 +291,20 @@ func (s *Socket) deadlineCrossed() bool {
 func (s *Socket) streamMessagesAsync() {
 	var cachedLSN *pglogrepl.LSN
 	for {
+		// changes up to the last received commit have all been emitted
+		select {
+		case <-s.ctx.Done():
+			logger.Info("Stopping replication; confirming received LSN...")
+			if cachedLSN == nil {
+				s.err <- nil
+				return
+			}
+
+			s.err <- s.AcknowledgeLSN(*cachedLSN)
+			return
+		default:
+		}
+
 		exit, err := func() (bool, error) {
 			if s.deadlineCrossed() {
 				// adjusting with function being retriggered when not even a single message has been received
@@ -186,58 +312,23 @@ func (s *Socket) streamMessagesAsync() {
 				return true, nil
 			}
 
-			ctx, cancel := context.WithDeadline(context.Background(), s.nextStandbyMessageDeadline)
-			defer cancel()
-
-			rawMsg, err := s.pgConn.ReceiveMessage(ctx)
-			if err != nil {
-				if pgconn.Timeout(err) || err == io.EOF || err == io.ErrUnexpectedEOF {
-					return true, nil
-				}
-
-				return false, fmt.Errorf("failed to receive messages from PostgreSQL %s", err)
+			// cancellation is checked at least every standby message timeout
+			deadline := s.nextStandbyMessageDeadline
+			if limit := time.Now().Add(s.standbyMessageTimeout); deadline.After(limit) {
+				deadline = limit
 			}
 
-			if errMsg, ok := rawMsg.(*pgproto3.ErrorResponse); ok {
-				return false, fmt.Errorf("received broken Postgres WAL. Error: %+v", errMsg)
+			timeout, replyRequested, lsn, err := s.receive(deadline)
+			if err != nil || timeout {
+				return timeout && s.deadlineCrossed(), err
 			}
 
-			msg, ok := rawMsg.(*pgproto3.CopyData)
-			if !ok {
-				return false, fmt.Errorf("received unexpected message: %T", rawMsg)
+			if replyRequested {
+				s.nextStandbyMessageDeadline = time.Time{}
 			}
 
-			switch msg.Data[0] {
-			case pglogrepl.PrimaryKeepaliveMessageByteID:
-				pkm, err := pglogrepl.ParsePrimaryKeepaliveMessage(msg.Data[1:])
-				if err != nil {
-					return false, fmt.Errorf("ParsePrimaryKeepaliveMessage failed: %s", err)
-				}
-
-				if pkm.ReplyRequested {
-					s.nextStandbyMessageDeadline = time.Time{}
-				}
-
-			case pglogrepl.XLogDataByteID:
-				xld, err := pglogrepl.ParseXLogData(msg.Data[1:])
-				if err != nil {
-					return false, fmt.Errorf("ParseXLogData failed: %s", err)
-				}
-
-				// Cache LSN here to be used during acknowledgement
-				clientXLogPos := xld.WALStart + pglogrepl.LSN(len(xld.WALData))
-				cachedLSN = &clientXLogPos
-				err = s.changeFilter.FilterChange(clientXLogPos, xld.WALData, func(change WalJSChange) {
-					s.messages <- change
-
-					// stop waiter after a record has been recieved
-					if s.waiter != nil {
-						s.waiter.Stop()
-					}
-				})
-				if err != nil {
-					return false, err
-				}
+			if lsn != nil {
+				cachedLSN = lsn
 			}
 
 			s.increaseDeadline()
@@ -251,39 +342,221 @@ func (s *Socket) streamMessagesAsync() {
 
 		// acknowledge and exit only when we can acknowledge a LSN
 		// This helps in hooking till atleast getting one message from
-		if exit && cachedLSN != nil {
+		if exit && cachedLSN != nil && !s.inTransaction && !s.snapshotting.Load() {
 			s.err <- s.AcknowledgeLSN(*cachedLSN)
 			break
 		}
 	}
 }
 
+// followMessagesAsync streams changes until stopped; received positions are checkpointed
+// through messages and confirmed to postgres once the checkpoint is reached
+func (s *Socket) followMessagesAsync() {
+	var cachedLSN *pglogrepl.LSN
+	checkpointed := s.clientXLogPos
+	nextStatus := time.Now().Add(s.StandbyInterval)
+
+	// checkpoint pushes a checkpoint after received changes; done is closed once reached
+	checkpoint := func() chan struct{} {
+		done := make(chan struct{})
+		if cachedLSN == nil || *cachedLSN <= checkpointed {
+			close(done)
+			return done
+		}
+
+		lsn := *cachedLSN
+		checkpointed = lsn
+		s.messages <- WalJSChange{
+			Kind: "checkpoint",
+			LSN:  &lsn,
+			Checkpoint: func() {
+				s.confirm(lsn)
+				close(done)
+			},
+		}
+
+		return done
+	}
+
+	for {
+		if s.stopped() {
+			logger.Info("Stopping replication; confirming flushed LSN...")
+			<-checkpoint()
+
+			s.err <- s.sendStandbyStatus()
+			return
+		}
+
+		if time.Now().After(nextStatus) {
+			checkpoint()
+
+			if err := s.sendStandbyStatus(); err != nil {
+				s.err <- err
+				return
+			}
+
+			nextStatus = time.Now().Add(s.StandbyInterval)
+		}
+
+		deadline := time.Now().Add(s.standbyMessageTimeout)
+		if deadline.After(nextStatus) {
+			deadline = nextStatus
+		}
+
+		timeout, replyRequested, lsn, err := s.receive(deadline)
+		if err != nil {
+			s.err <- err
+			return
+		}
+
+		if timeout {
+			continue
+		}
+
+		if lsn != nil {
+			cachedLSN = lsn
+		}
+
+		if replyRequested {
+			nextStatus = time.Time{}
+		}
+	}
+}
+
+// stopped returns true once following is stopped or ctx is done
+func (s *Socket) stopped() bool {
+	select {
+	case <-s.Done:
+		return true
+	case <-s.ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// receive handles a single replication message; returns position of a received commit
+// as only transaction boundaries can be acknowledged
+//
+// Receiving isn't bound to s.ctx since canceling it closes the connection
+func (s *Socket) receive(deadline time.Time) (bool, bool, *pglogrepl.LSN, error) {
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	rawMsg, err := s.pgConn.ReceiveMessage(ctx)
+	if err != nil {
+		if pgconn.Timeout(err) || err == io.EOF || err == io.ErrUnexpectedEOF {
+			return true, false, nil, nil
+		}
+
+		return false, false, nil, fmt.Errorf("failed to receive messages from PostgreSQL %s", err)
+	}
+
+	if errMsg, ok := rawMsg.(*pgproto3.ErrorResponse); ok {
+		return false, false, nil, fmt.Errorf("received broken Postgres WAL. Error: %+v", errMsg)
+	}
+
+	msg, ok := rawMsg.(*pgproto3.CopyData)
+	if !ok {
+		return false, false, nil, fmt.Errorf("received unexpected message: %T", rawMsg)
+	}
+
+	switch msg.Data[0] {
+	case pglogrepl.PrimaryKeepaliveMessageByteID:
+		pkm, err := pglogrepl.ParsePrimaryKeepaliveMessage(msg.Data[1:])
+		if err != nil {
+			return false, false, nil, fmt.Errorf("ParsePrimaryKeepaliveMessage failed: %s", err)
+		}
+
+		return false, pkm.ReplyRequested, nil, nil
+	case pglogrepl.XLogDataByteID:
+		xld, err := pglogrepl.ParseXLogData(msg.Data[1:])
+		if err != nil {
+			return false, false, nil, fmt.Errorf("ParseXLogData failed: %s", err)
+		}
+
+		// Cache LSN here to be used during acknowledgement
+		clientXLogPos := xld.WALStart + pglogrepl.LSN(len(xld.WALData))
+		var committed *pglogrepl.LSN
+		err = s.changeFilter.FilterChange(clientXLogPos, xld.WALData, func(change WalJSChange) {
+			switch change.Kind {
+			case "begin":
+				s.inTransaction = true
+				return
+			case "commit":
+				s.inTransaction = false
+				committed = change.LSN
+			case "signal":
+				s.onSignal(change)
+				return
+			default:
+				exclude(change)
+				s.dropFromWindow(change)
+			}
+
+			s.messages <- change
+
+			// stop waiter after a record has been recieved
+			if s.waiter != nil {
+				s.waiter.Stop()
+			}
+		})
+		if err != nil {
+			return false, false, nil, err
+		}
+
+		return false, false, committed, nil
+	}
+
+	return false, false, nil, nil
+}
+
 func (s *Socket) start() {
+	incremental := []protocol.Stream{}
 	for _, stream := range s.Config.FullSyncTables.Array() {
-		err := func() error {
-			snapshotter := NewSnapshotter(stream, int(stream.BatchSize()))
-			if err := snapshotter.Prepare(s.pgxConn); err != nil {
-				return fmt.Errorf("failed to prepare database snapshot: %s", err)
+		// chunks are ordered by primary key
+		if s.SignalTable != "" {
+			if len(jdbc.FullRefreshKeys(stream)) > 0 {
+				incremental = append(incremental, stream)
+				continue
 			}
 
+			logger.Warnf("%s has no primary key; snapshotting before replication", stream.ID())
+		}
+
+		err := func() error {
+			snapshotter := NewSnapshotter(stream, int(stream.BatchSize()))
+			// snapshots are read on their own connection as closing it ends the snapshot
 			defer func() {
 				snapshotter.ReleaseSnapshot()
 				snapshotter.CloseConn()
 			}()
 
+			if err := snapshotter.Prepare(s.ctx, s.dsn); err != nil {
+				return fmt.Errorf("failed to prepare database snapshot: %s", err)
+			}
+
 			logger.Infof("Processing database snapshot: %s", stream.ID())
 			logger.Info("Query snapshot", "batch-size", stream.BatchSize())
 
 			intialState := stream.InitialState()
-			args := []any{}
-			statement := jdbc.PostgresWithoutState(stream)
+			statement, args, err := jdbc.PostgresWithoutState(stream)
 			if intialState != nil {
 				logger.Debugf("Using Initial state for stream %s : %v", stream.ID(), intialState)
-				statement = jdbc.PostgresWithState(stream)
-				args = append(args, intialState)
+				statement, args, err = jdbc.PostgresWithState(stream, intialState)
+			}
+			if err != nil {
+				return err
+			}
+
+			setter := jdbc.NewReader(s.ctx, statement, int(stream.BatchSize()), snapshotter.tx.Query, args...)
+			if keys := jdbc.IncrementalKeys(stream); len(keys) > 0 {
+				setter.WithKeyset(keys, rowKey(keys))
+			} else if len(stream.Self().CursorFields()) == 0 {
+				// replicated tables store rows
+				setter.WithOffset(jdbc.PostgresRowOrder(stream, true))
 			}
 
-			setter := jdbc.NewReader(context.TODO(), statement, int(stream.BatchSize()), snapshotter.tx.Query, args...)
 			return setter.Capture(func(rows pgx.Rows) error {
 				values, err := rows.Values()
 				if err != nil {
@@ -326,9 +599,20 @@ func (s *Socket) start() {
 		return
 	}
 
+	if len(incremental) > 0 {
+		s.snapshotting.Store(true)
+		go s.incrementalSnapshot(incremental)
+	}
+
+	if s.Done != nil {
+		go s.followMessagesAsync()
+		return
+	}
+
 	go s.streamMessagesAsync()
 }
 
+// OnMessage calls callback with changes until streaming is completed
 func (s *Socket) OnMessage(callback OnMessage) error {
 	go s.start()
 
@@ -347,19 +631,24 @@ func (s *Socket) OnMessage(callback OnMessage) error {
 	}
 }
 
-// cleanUpOnFailure drops replication slot and publication if database snapshotting was failed for any reason
+// cleanup closes connections once streaming stops; closing isn't bound to s.ctx as it's
+// usually done by then and canceling skips terminating connections gracefully
 func (s *Socket) cleanup() {
-	s.pgConn.Close(context.TODO())
-	s.pgxConn.Close(context.TODO())
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+
+	s.pgConn.Close(ctx)
+	s.pgxConn.Close(ctx)
 }
 
-func (s *Socket) Stop() error {
+// Stop closes replication connection; ctx bounds closing it
+func (s *Socket) Stop(ctx context.Context) error {
 	if s.pgConn != nil {
 		if s.waiter != nil {
 			s.waiter.Stop()
 		}
 
-		return s.pgConn.Close(context.TODO())
+		return s.pgConn.Close(ctx)
 	}
 
 	return nil
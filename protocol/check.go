@@ -32,16 +32,19 @@ var CheckCmd = &cobra.Command{
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := runContext(cmd.Context())
+		defer cancel()
+
 		err := func() error {
 			// Catalog has been passed setup and is driver; Connector should be setup
 			if isDriver && catalog != nil {
-				err := _rawConnector.Setup()
+				err := _driver.Setup(ctx)
 				if err != nil {
 					return err
 				}
 
 				// Get Source Streams
-				streams, err := _driver.Discover()
+				streams, err := _driver.Discover(ctx)
 				if err != nil {
 					return err
 				}
@@ -76,7 +79,13 @@ var CheckCmd = &cobra.Command{
 				}
 			} else {
 				// Only perform checks
-				err := _rawConnector.Check()
+				var err error
+				if isDriver {
+					err = _driver.Check(ctx)
+				} else {
+					err = _adapter.Check()
+				}
+
 				if err != nil {
 					return err
 				}
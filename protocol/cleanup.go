@@ -0,0 +1,43 @@
+package protocol
+
+import (
+	"fmt"
+
+	"github.com/gear5sh/gear5/logger"
+	"github.com/gear5sh/gear5/utils"
+	"github.com/spf13/cobra"
+)
+
+// CleanupCmd releases resources held by the driver in the source e.g. replication slots
+var CleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Gear5 cleanup command",
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if config_ == "" {
+			return fmt.Errorf("--config not passed")
+		} else {
+			if err := utils.UnmarshalFile(config_, _rawConnector.Config()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		driver, yes := implementation(_driver).(Cleaner)
+		if !yes {
+			return fmt.Errorf("%s does not support cleanup", _driver.Type())
+		}
+
+		ctx, cancel := runContext(cmd.Context())
+		defer cancel()
+
+		if err := driver.Cleanup(ctx); err != nil {
+			return fmt.Errorf("failed to cleanup: %s", err)
+		}
+
+		logger.Infof("Cleaned up %s", _driver.Type())
+
+		return nil
+	},
+}
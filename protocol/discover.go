@@ -29,12 +29,15 @@ var DiscoverCmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		err := _driver.Setup()
+		ctx, cancel := runContext(cmd.Context())
+		defer cancel()
+
+		err := _driver.Setup(ctx)
 		if err != nil {
 			return err
 		}
 
-		streams, err := _driver.Discover()
+		streams, err := _driver.Discover(ctx)
 		if err != nil {
 			return err
 		}
@@ -60,7 +63,7 @@ var DiscoverCmd = &cobra.Command{
 				channel := make(chan types.Record, recordsPerStream)
 				count := 0
 				go func() {
-					err := _driver.Read(stream.Wrap(recordsPerStream), channel)
+					err := _driver.Read(ctx, stream.Wrap(recordsPerStream), channel)
 					if err != nil {
 						logger.Fatalf("Error occurred while reading records from [%s]: %s", stream.Name, err)
 					}
@@ -1,6 +1,8 @@
 package protocol
 
 import (
+	"context"
+
 	"github.com/gear5sh/gear5/types"
 )
 
@@ -28,29 +30,114 @@ type Driver interface {
 	//
 	// TODO: Remove error return in future if not required
 	Discover() ([]*types.Stream, error)
-	Read(stream Stream, channel chan<- types.Record) error
+	// Read reads stream into channel; it must stop once ctx is done leaving stream
+	// state consistent with records already sent
+	Read(ctx context.Context, stream Stream, channel chan<- types.Record) error
 	BulkRead() bool
 }
 
 // Bulk Read Driver
 type BulkDriver interface {
-	GroupRead(channel chan<- types.Record, streams ...Stream) error
+	// GroupRead reads streams together into channel; it must stop once ctx is done
+	// leaving state consistent with records already sent
+	GroupRead(ctx context.Context, channel chan<- types.Record, streams ...Stream) error
 	SetupGlobalState(state *types.State) error
 	StateType() types.StateType
 }
 
+// Context aware Driver; every call stops once ctx is done so that timeouts and
+// cancellation are enforced per run and per stream. Drivers are adapted from Driver
+// by AdaptDriver
+type DriverV2 interface {
+	// Setting up config reference in driver i.e. must be pointer
+	Config() any
+	Spec() any
+	// Sets up connections and perform checks; doesn't load Streams
+	Check(ctx context.Context) error
+	// Composition with Check; loads/setup streams as well
+	Setup(ctx context.Context) error
+	// Discover returns cached streams
+	Discover(ctx context.Context) ([]*types.Stream, error)
+	// Read reads stream into channel; it must stop once ctx is done leaving stream
+	// state consistent with records already sent
+	Read(ctx context.Context, stream Stream, channel chan<- types.Record) error
+	BulkRead() bool
+	Type() string
+}
+
+// Context aware Bulk Read Driver
+type BulkDriverV2 interface {
+	// GroupRead reads streams together into channel; it must stop once ctx is done
+	// leaving state consistent with records already sent
+	GroupRead(ctx context.Context, channel chan<- types.Record, streams ...Stream) error
+	SetupGlobalState(ctx context.Context, state *types.State) error
+	StateType() types.StateType
+}
+
+// Bulk Driver that can stream changes continuously
+type Follower interface {
+	// Follow keeps GroupRead streaming until ctx is done
+	Follow(ctx context.Context)
+}
+
+// Driver holding resources in the source that outlive a sync
+type Cleaner interface {
+	// Cleanup releases resources e.g. replication slots once a connection is retired;
+	// it must stop once ctx is done
+	Cleanup(ctx context.Context) error
+}
+
+// Bulk Driver whose global state holds a position in a change log of the source
+type Rewinder interface {
+	// CheckRewind fails unless the next GroupRead can resume streaming changes from position
+	CheckRewind(ctx context.Context, position string) error
+}
+
 // JDBC Driver
 type JDBCDriver interface {
-	FullLoad(stream Stream, channel chan<- types.Record) error
-	GroupRead(channel chan<- types.Record, streams ...Stream) error
+	FullLoad(ctx context.Context, stream Stream, channel chan<- types.Record) error
+	GroupRead(ctx context.Context, channel chan<- types.Record, streams ...Stream) error
 	SetupGlobalState(state *types.State) error
 	StateType() types.StateType
 }
 
+// Context aware JDBC Driver
+type JDBCDriverV2 interface {
+	FullLoad(ctx context.Context, stream Stream, channel chan<- types.Record) error
+	BulkDriverV2
+}
+
 type Adapter interface {
 	Connector
+	// Write consumes records until channel is closed; it must return nil only once
+	// every record received has been durably persisted in the destination
 	Write(channel <-chan types.Record) error
-	Create(streamName string) error
+	// Create prepares destination for a configured stream before any record is written
+	Create(stream Stream) error
+}
+
+// Adapter applying schema changes emitted as actions by drivers
+type ActionAdapter interface {
+	// Act applies action on destination of stream; stream schema already carries
+	// the altered columns
+	Act(stream Stream, action *types.ActionRow) error
+}
+
+// Adapter holding destination resources e.g. open files across writes
+type ClosingAdapter interface {
+	// Close finalizes destination once no further records are written
+	Close() error
+}
+
+// Store of state loaded before a read and checkpointed while reading
+type StateStore interface {
+	// Lock fails with statestore.ErrLocked while another run holds the store
+	Lock(ctx context.Context) error
+	// Load returns nil if no state has been saved
+	Load(ctx context.Context) (*types.State, error)
+	Save(ctx context.Context, state *types.State) error
+	// Close releases the lock
+	Close() error
 }
 
 type Stream interface {
@@ -66,6 +153,9 @@ type Stream interface {
 	InitialState() any
 	GetState() any
 	SetState(value any)
+	// State values other than cursor e.g. progress of a full load
+	GetStateKey(key string) any
+	SetStateKey(key string, value any)
 	BatchSize() int
 	SetBatchSize(size int)
 	Validate(source *types.Stream) error
@@ -1,12 +1,17 @@
 package protocol
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gear5sh/gear5/logger"
+	"github.com/gear5sh/gear5/pkg/statestore"
 	"github.com/gear5sh/gear5/types"
 	"github.com/gear5sh/gear5/utils"
 	"github.com/spf13/cobra"
@@ -32,30 +37,95 @@ var ReadCmd = &cobra.Command{
 			}
 		}
 
-		if state_ != "" {
-			state = &types.State{}
-			if err := utils.UnmarshalFile(state_, state); err != nil {
+		if stateBackend_ != "" {
+			store, err := openStateStore(cmd.Context(), stateBackend_)
+			if err != nil {
+				return err
+			}
+
+			stateStore = store
+		} else if state_ != "" {
+			data, err := os.ReadFile(state_)
+			if err != nil {
+				return fmt.Errorf("failed to read state: %s", err)
+			}
+
+			state, err = types.ParseState(data)
+			if err != nil {
 				return err
 			}
 		}
 
+		if concurrency_ == 0 {
+			return fmt.Errorf("--concurrency must be at least 1")
+		}
+
+		if onStreamError_ != failFast && onStreamError_ != continueOnError {
+			return fmt.Errorf("invalid --on-stream-error %s; valid are %s, %s", onStreamError_, failFast, continueOnError)
+		}
+
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if stateStore != nil {
+			defer stateStore.Close()
+		}
+
+		// reads stop on SIGINT and SIGTERM; records sent so far are emitted along with final
+		// state. A second signal terminates immediately
+		signalled, stop := signal.NotifyContext(cmd.Context(), syscall.SIGTERM, os.Interrupt)
+		defer stop()
+		context.AfterFunc(signalled, stop)
+
+		ctx, cancel := runContext(signalled)
+		defer cancel()
+
 		// Driver Setup
-		err := _driver.Setup()
+		err := _driver.Setup(ctx)
 		if err != nil {
 			return err
 		}
 
+		// state is kept by streams unless driver reads streams together
+		stateType := types.StreamType
+		if driver, yes := bulkDriver(_driver); yes && _driver.BulkRead() {
+			stateType = driver.StateType()
+		}
+
 		// Setup state defaults
 		if state == nil {
 			state = &types.State{
-				Type: types.StreamType,
+				Version: types.StateVersion,
+				Type:    stateType,
 			}
 		}
 		state.Mutex = &sync.Mutex{}
 
+		// sync method of connection changed since state was saved
+		if state.Type != stateType {
+			logger.Infof("Migrating state from %s to %s", state.Type, stateType)
+			if err := state.MigrateType(stateType); err != nil {
+				return err
+			}
+		}
+
+		// state is emitted and saved to state store; first failure to save is returned
+		var saveErr error
+		checkpoint := func() {
+			logger.LogState(state)
+			if stateStore == nil {
+				return
+			}
+
+			// saved with command context as final state is saved once reads are stopped
+			if err := stateStore.Save(cmd.Context(), state); err != nil {
+				logger.Errorf("failed to save state: %s", err)
+				if saveErr == nil {
+					saveErr = err
+				}
+			}
+		}
+
 		// Setting Record iteration
 		recordStream := make(chan types.Record, 2*batchSize_)
 		numRecords := int64(0)
@@ -73,15 +143,29 @@ var ReadCmd = &cobra.Command{
 					break
 				}
 
+				// schema changes found while reading
+				if message.Action != nil {
+					logger.LogAction(message.Action)
+					if message.Action.Type == types.ALTER {
+						logger.LogConfiguredCatalog(catalog)
+					}
+					continue
+				}
+
+				// progress is committed to state only after preceding records are emitted
+				if message.Checkpoint != nil {
+					message.Checkpoint()
+					checkpoint()
+					continue
+				}
+
 				logger.LogRecord(message)
 				numRecords++
 				batch++
 
 				// log state after a batch
 				if batch >= batchSize_ {
-					if !state.IsZero() {
-						logger.LogState(state)
-					}
+					checkpoint()
 					// reset batch
 					batch = 0
 				}
@@ -89,7 +173,7 @@ var ReadCmd = &cobra.Command{
 		}()
 
 		// Get Source Streams
-		streams, err := _driver.Discover()
+		streams, err := _driver.Discover(ctx)
 		if err != nil {
 			return err
 		}
@@ -99,6 +183,7 @@ var ReadCmd = &cobra.Command{
 		// Validating Streams and attaching State
 		selectedStreams := []string{}
 		validStreams := []Stream{}
+		actions := []*types.ActionRow{}
 		_, _ = utils.ArrayContains(catalog.Streams, func(elem *types.ConfiguredStream) bool {
 			source, found := streamsMap[elem.ID()]
 			if !found {
@@ -112,11 +197,38 @@ var ReadCmd = &cobra.Command{
 				return false
 			}
 
+			// excluded columns are neither read nor written
+			elem.Stream.Schema = elem.Schema().Without(elem.ExcludeColumns...)
+
+			// destination must be altered for columns added or changed since catalog was generated
+			if source.Schema != nil {
+				schema := source.Schema.Without(elem.ExcludeColumns...)
+				if diff := schema.Diff(elem.Schema()); len(diff) > 0 {
+					actions = append(actions, &types.ActionRow{
+						Type:      types.ALTER,
+						Namespace: elem.Namespace(),
+						Stream:    elem.Name(),
+						Columns:   diff,
+					})
+
+					elem.Stream.Schema = schema
+				}
+			}
+
 			err = elem.SetupState(state, int(batchSize_))
 			if err != nil {
 				logger.Warnf("failed to set stream[%s] state due to reason: %s", elem.ID(), err)
 			}
 
+			// full refresh replaces destination data unless an interrupted load is resumed
+			if elem.GetSyncMode() == types.FULLREFRESH && !elem.HasState() {
+				actions = append(actions, &types.ActionRow{
+					Type:      types.TRUNCATE,
+					Namespace: elem.Namespace(),
+					Stream:    elem.Name(),
+				})
+			}
+
 			selectedStreams = append(selectedStreams, elem.ID())
 			validStreams = append(validStreams, elem)
 			return false
@@ -124,35 +236,62 @@ var ReadCmd = &cobra.Command{
 
 		logger.Infof("Valid selected streams are %s", strings.Join(selectedStreams, ", "))
 
+		// actions are emitted before any record is read
+		for _, action := range actions {
+			logger.LogAction(action)
+		}
+
+		var readErr error
+
 		// Driver running on GroupRead
 		if _driver.BulkRead() {
-			driver, yes := _driver.(BulkDriver)
+			driver, yes := bulkDriver(_driver)
 			if !yes {
 				return fmt.Errorf("%s does not implement BulkDriver", _driver.Type())
 			}
 
 			// Setup Global State from Connector
-			if err := driver.SetupGlobalState(state); err != nil {
+			if err := driver.SetupGlobalState(ctx, state); err != nil {
 				return err
 			}
 
-			err := driver.GroupRead(recordStream, validStreams...)
-			if err != nil {
-				return fmt.Errorf("error occurred while reading records: %s", err)
+			if streamTimeout_ > 0 {
+				logger.Warnf("--stream-timeout is ignored as streams are read together")
 			}
-		} else {
-			// Driver running on Stream mode
-			for _, stream := range validStreams {
-				logger.Infof("Reading stream %s", stream.ID())
 
-				streamStartTime := time.Now()
-				err := _driver.Read(stream, recordStream)
-				if err != nil {
-					return fmt.Errorf("error occurred while reading records: %s", err)
+			if follow_ {
+				follower, yes := implementation(_driver).(Follower)
+				if !yes {
+					return fmt.Errorf("%s does not support --follow", _driver.Type())
 				}
 
-				logger.Infof("Finished reading stream %s[%s] in %s", stream.Name(), stream.Namespace(), time.Since(streamStartTime).String())
+				// stream until terminated
+				follower.Follow(ctx)
+			}
+
+			if err := driver.GroupRead(ctx, recordStream, validStreams...); err != nil {
+				readErr = fmt.Errorf("error occurred while reading records: %s", err)
 			}
+		} else {
+			if follow_ {
+				return fmt.Errorf("--follow is only supported in CDC mode")
+			}
+
+			// Driver running on Stream mode; state of streams read is emitted even if others failed
+			readErr = readStreams(ctx, validStreams, recordStream)
+		}
+
+		// errors of reads stopped by a signal are expected; following until --timeout is
+		// stopping alike
+		switch {
+		case signalled.Err() != nil, follow_ && ctx.Err() != nil:
+			logger.Infof("Stopped reading")
+			if readErr != nil {
+				logger.Warnf("Read interrupted: %s", readErr)
+				readErr = nil
+			}
+		case ctx.Err() != nil:
+			readErr = fmt.Errorf("read exceeded --timeout of %s", timeout_)
 		}
 
 		// stop record iteration
@@ -162,10 +301,156 @@ var ReadCmd = &cobra.Command{
 		recordIterationWait.Wait()
 
 		logger.Infof("Total records read: %d", numRecords)
-		if !state.IsZero() {
-			logger.LogState(state)
+		checkpoint()
+
+		if readErr != nil {
+			return readErr
+		}
+
+		if saveErr != nil {
+			return fmt.Errorf("failed to save state: %s", saveErr)
 		}
 
 		return nil
 	},
 }
+
+// openStateStore locks state store of backend and loads state from it
+func openStateStore(ctx context.Context, backend string) (StateStore, error) {
+	var store StateStore
+	switch backend {
+	case statestore.File:
+		location := stateLocation_
+		if location == "" {
+			location = state_
+		}
+
+		if location == "" {
+			return nil, fmt.Errorf("--state-location or --state is required with %s state backend", statestore.File)
+		}
+
+		store = statestore.NewFileStore(location)
+	case statestore.Postgres:
+		if state_ != "" {
+			return nil, fmt.Errorf("--state can't be combined with %s state backend", statestore.Postgres)
+		}
+
+		postgres, err := statestore.NewPostgresStore(ctx, stateLocation_, stateKey_)
+		if err != nil {
+			return nil, err
+		}
+
+		store = postgres
+	default:
+		return nil, fmt.Errorf("invalid --state-backend %s; valid are %s, %s", backend, statestore.File, statestore.Postgres)
+	}
+
+	if err := store.Lock(ctx); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to lock state store: %s", err)
+	}
+
+	loaded, err := store.Load(ctx)
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	if loaded != nil {
+		state = loaded
+	}
+
+	return store, nil
+}
+
+// policies on failure of a stream
+const (
+	failFast        = "fail-fast"
+	continueOnError = "continue"
+)
+
+// readStreams reads streams concurrently into channel; on failure of a stream no further
+// streams are started with fail-fast while remaining streams are still read with continue.
+// No further streams are started once ctx is done; a stream exceeding --stream-timeout fails
+func readStreams(ctx context.Context, streams []Stream, channel chan<- types.Record) error {
+	queue := make(chan Stream, len(streams))
+	for _, stream := range streams {
+		queue <- stream
+	}
+	close(queue)
+
+	mu := sync.Mutex{}
+	failed := []string{}
+	var firstErr error
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < int(min(concurrency_, uint(len(streams)))); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for stream := range queue {
+				mu.Lock()
+				stop := firstErr != nil && onStreamError_ == failFast
+				mu.Unlock()
+				if stop || ctx.Err() != nil {
+					return
+				}
+
+				logger.Infof("Reading stream %s", stream.ID())
+
+				streamStartTime := time.Now()
+				if err := readStream(ctx, stream, channel); err != nil {
+					if ctx.Err() != nil {
+						logger.Warnf("Stopped reading stream %s: %s", stream.ID(), err)
+						continue
+					}
+
+					logger.Errorf("Failed reading stream %s: %s", stream.ID(), err)
+
+					mu.Lock()
+					failed = append(failed, stream.ID())
+					if firstErr == nil {
+						firstErr = fmt.Errorf("error occurred while reading records of %s: %s", stream.ID(), err)
+					}
+					mu.Unlock()
+					continue
+				}
+
+				logger.Infof("Finished reading stream %s[%s] in %s", stream.Name(), stream.Namespace(), time.Since(streamStartTime).String())
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if len(failed) > 1 {
+		return fmt.Errorf("%d streams failed [%s]; first: %s", len(failed), strings.Join(failed, ", "), firstErr)
+	}
+
+	return firstErr
+}
+
+// readStream reads stream bounded by --stream-timeout
+func readStream(ctx context.Context, stream Stream, channel chan<- types.Record) error {
+	if streamTimeout_ <= 0 {
+		return _driver.Read(ctx, stream, channel)
+	}
+
+	streamCtx, cancel := context.WithTimeout(ctx, streamTimeout_)
+	defer cancel()
+
+	err := _driver.Read(streamCtx, stream, channel)
+	if err != nil && streamCtx.Err() != nil && ctx.Err() == nil {
+		return fmt.Errorf("exceeded --stream-timeout of %s: %s", streamTimeout_, err)
+	}
+
+	return err
+}
+
+func init() {
+	ReadCmd.Flags().BoolVarP(&follow_, "follow", "", false, "(Optional) Keep streaming changes until terminated")
+	ReadCmd.Flags().DurationVarP(&streamTimeout_, "stream-timeout", "", 0, "(Optional) Time limit of reading a stream e.g. 30m; unlimited if 0")
+	ReadCmd.Flags().UintVarP(&concurrency_, "concurrency", "", 1, "(Optional) Number of streams read in parallel")
+	ReadCmd.Flags().StringVarP(&onStreamError_, "on-stream-error", "", failFast, "(Optional) Policy on failure of a stream; fail-fast or continue")
+}
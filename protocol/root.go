@@ -1,7 +1,9 @@
 package protocol
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/gear5sh/gear5/logger/console"
 	"github.com/gear5sh/gear5/types"
@@ -15,19 +17,37 @@ var (
 	state_     string
 	catalog_   string
 	batchSize_ uint
+	follow_    bool
+	timeout_   time.Duration
 
-	catalog *types.Catalog
-	state   *types.State
+	concurrency_   uint
+	onStreamError_ string
+	streamTimeout_ time.Duration
+
+	stateBackend_  string
+	stateLocation_ string
+	stateKey_      string
+
+	catalog    *types.Catalog
+	state      *types.State
+	stateStore StateStore // set if state is kept in a state backend
 
 	isDriver        = false
 	driverCommands  = []*cobra.Command{}
 	adapterCommands = []*cobra.Command{}
 
-	_driver       Driver
+	_driver       DriverV2
 	_adapter      Adapter
-	_rawConnector Connector
+	_rawConnector configurable
 )
 
+// configurable is implemented by drivers and adapters alike
+type configurable interface {
+	Config() any
+	Spec() any
+	Type() string
+}
+
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
 	Use:   "gear5",
@@ -48,18 +68,31 @@ var RootCmd = &cobra.Command{
 func CreateRootCommand(forDriver bool, connector any) *cobra.Command {
 	if forDriver {
 		RootCmd.AddCommand(driverCommands...)
-		_driver = connector.(Driver)
+		if driver, yes := connector.(Driver); yes {
+			_driver = AdaptDriver(driver)
+		} else {
+			_driver = connector.(DriverV2)
+		}
 		isDriver = true
 	} else {
 		RootCmd.AddCommand(adapterCommands...)
 		_adapter = connector.(Adapter)
 	}
 
-	_rawConnector = connector.(Connector)
+	_rawConnector = connector.(configurable)
 
 	return RootCmd
 }
 
+// runContext bounds ctx by --timeout
+func runContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout_ <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout_)
+}
+
 func getAvailableCommands() []*cobra.Command {
 	if isDriver {
 		return driverCommands
@@ -69,13 +102,17 @@ func getAvailableCommands() []*cobra.Command {
 }
 
 func init() {
-	driverCommands = append(driverCommands, SpecCmd, CheckCmd, DiscoverCmd, ReadCmd)
+	driverCommands = append(driverCommands, SpecCmd, CheckCmd, DiscoverCmd, ReadCmd, CleanupCmd, StateCmd)
 	adapterCommands = append(adapterCommands, SpecCmd, CheckCmd, DiscoverCmd, WriteCmd)
 
 	RootCmd.PersistentFlags().StringVarP(&config_, "config", "", "", "(Required) Config for connector")
 	RootCmd.PersistentFlags().StringVarP(&catalog_, "catalog", "", "", "(Required) Catalog for connector")
 	RootCmd.PersistentFlags().StringVarP(&state_, "state", "", "", "(Required) State for connector")
 	RootCmd.PersistentFlags().UintVarP(&batchSize_, "batch", "", 10000, "(Optional) Batch size for connector")
+	RootCmd.PersistentFlags().DurationVarP(&timeout_, "timeout", "", 0, "(Optional) Time limit of a run e.g. 2h; unlimited if 0")
+	RootCmd.PersistentFlags().StringVarP(&stateBackend_, "state-backend", "", "", "(Optional) Load state from and save it to a backend; file or postgres")
+	RootCmd.PersistentFlags().StringVarP(&stateLocation_, "state-location", "", "", "(Optional) Path of file state backend; defaults to --state. Connection URL of postgres state backend")
+	RootCmd.PersistentFlags().StringVarP(&stateKey_, "state-key", "", "", "(Optional) Key of state in postgres state backend")
 
 	// Disable Cobra CLI's built-in usage and error handling
 	RootCmd.SilenceUsage = true
@@ -0,0 +1,76 @@
+package protocol
+
+import (
+	"context"
+
+	"github.com/gear5sh/gear5/types"
+)
+
+// AdaptDriver adapts a Driver to DriverV2 by Check, Setup and Discover; Read and GroupRead
+// of Driver already take a context. Calls without a context can't be stopped and complete
+// before ctx is checked
+func AdaptDriver(driver Driver) DriverV2 {
+	return &driverShim{Driver: driver}
+}
+
+type driverShim struct {
+	Driver
+}
+
+func (d *driverShim) Check(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return d.Driver.Check()
+}
+
+func (d *driverShim) Setup(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return d.Driver.Setup()
+}
+
+func (d *driverShim) Discover(ctx context.Context) ([]*types.Stream, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return d.Driver.Discover()
+}
+
+type bulkDriverShim struct {
+	BulkDriver
+}
+
+func (b *bulkDriverShim) SetupGlobalState(ctx context.Context, state *types.State) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.BulkDriver.SetupGlobalState(state)
+}
+
+// implementation returns driver adapted by AdaptDriver; optional interfaces e.g. Follower
+// are asserted on it
+func implementation(driver DriverV2) any {
+	if shim, yes := driver.(*driverShim); yes {
+		return shim.Driver
+	}
+
+	return driver
+}
+
+// bulkDriver returns driver as BulkDriverV2; false if driver can't read streams together
+func bulkDriver(driver DriverV2) (BulkDriverV2, bool) {
+	switch bulk := implementation(driver).(type) {
+	case BulkDriverV2:
+		return bulk, true
+	case BulkDriver:
+		return &bulkDriverShim{BulkDriver: bulk}, true
+	}
+
+	return nil, false
+}
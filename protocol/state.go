@@ -0,0 +1,351 @@
+package protocol
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gear5sh/gear5/logger"
+	"github.com/gear5sh/gear5/pkg/statestore"
+	"github.com/gear5sh/gear5/types"
+	"github.com/gear5sh/gear5/typeutils"
+	"github.com/gear5sh/gear5/utils"
+	"github.com/goccy/go-json"
+	"github.com/jackc/pglogrepl"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stateStream_ string
+	cursorValue_ string
+	lsn_         string
+)
+
+// StateCmd inspects and edits state of a connection; state is loaded from and written back
+// to --state-backend, defaulting to the file of --state
+var StateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Gear5 state command",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if catalog_ != "" {
+			catalog = &types.Catalog{}
+			if err := utils.UnmarshalFile(catalog_, catalog); err != nil {
+				return err
+			}
+		}
+
+		backend := stateBackend_
+		if backend == "" {
+			backend = statestore.File
+		}
+
+		store, err := openStateStore(cmd.Context(), backend)
+		if err != nil {
+			return err
+		}
+		stateStore = store
+
+		if state == nil {
+			return nil
+		}
+		state.Mutex = &sync.Mutex{}
+
+		// state must be of a type the driver reads
+		valid := []types.StateType{types.StreamType}
+		if driver, yes := bulkDriver(_driver); yes {
+			valid = append(valid, driver.StateType())
+		}
+
+		if !utils.ExistInArray(valid, state.Type) {
+			stateStore.Close()
+			return fmt.Errorf("state type %s is not supported by %s; valid are %v", state.Type, _driver.Type(), valid)
+		}
+
+		return nil
+	},
+}
+
+var stateShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print state",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer stateStore.Close()
+
+		if state == nil {
+			return fmt.Errorf("no state found")
+		}
+
+		data, err := json.MarshalIndent(state, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal state: %s", err)
+		}
+
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return err
+	},
+}
+
+var stateResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Remove state of a stream so that it is read from scratch",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer stateStore.Close()
+
+		stream, err := configuredStream(stateStream_)
+		if err != nil {
+			return err
+		}
+
+		if state == nil {
+			return fmt.Errorf("no state found")
+		}
+
+		i, found := utils.ArrayContains(state.Streams, func(elem *types.StreamState) bool {
+			return elem.Namespace == stream.Namespace() && elem.Stream == stream.Name()
+		})
+		if !found {
+			return fmt.Errorf("stream %s not found in state", stream.ID())
+		}
+
+		state.Streams = append(state.Streams[:i], state.Streams[i+1:]...)
+
+		if err := stateStore.Save(cmd.Context(), state); err != nil {
+			return fmt.Errorf("failed to save state: %s", err)
+		}
+
+		logger.Infof("Reset state of %s", stream.ID())
+
+		return nil
+	},
+}
+
+var stateSetCursorCmd = &cobra.Command{
+	Use:   "set-cursor",
+	Short: "Set cursor value of a stream",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer stateStore.Close()
+
+		stream, err := configuredStream(stateStream_)
+		if err != nil {
+			return err
+		}
+
+		fields := stream.CursorFields()
+		if len(fields) == 0 {
+			return fmt.Errorf("stream %s has no cursor field", stream.ID())
+		}
+
+		value, err := cursorValue(stream, cursorValue_)
+		if err != nil {
+			return err
+		}
+
+		if state == nil {
+			state = &types.State{
+				Mutex:   &sync.Mutex{},
+				Version: types.StateVersion,
+				Type:    types.StreamType,
+			}
+		}
+
+		// stream or its cursor missing from state are set below
+		_ = stream.SetupState(state, int(batchSize_))
+		stream.SetState(value)
+
+		if err := stateStore.Save(cmd.Context(), state); err != nil {
+			return fmt.Errorf("failed to save state: %s", err)
+		}
+
+		logger.Infof("Set cursor of %s to %v", stream.ID(), value)
+
+		return nil
+	},
+}
+
+// stateRewindLSNCmd rewinds LSN of global state so that the next read streams changes again
+// from it; the driver checks the source can still stream from the LSN
+var stateRewindLSNCmd = &cobra.Command{
+	Use:   "rewind-lsn",
+	Short: "Rewind LSN of global CDC state",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer stateStore.Close()
+
+		driver, yes := implementation(_driver).(Rewinder)
+		if !yes {
+			return fmt.Errorf("%s does not support rewinding global state", _driver.Type())
+		}
+
+		// source is checked with connection of config
+		if config_ == "" {
+			return fmt.Errorf("--config not passed")
+		}
+
+		if err := utils.UnmarshalFile(config_, _rawConnector.Config()); err != nil {
+			return err
+		}
+
+		lsn, err := pglogrepl.ParseLSN(lsn_)
+		if err != nil {
+			return fmt.Errorf("invalid --lsn %s: %s", lsn_, err)
+		}
+
+		walState, current, err := globalLSN()
+		if err != nil {
+			return err
+		}
+
+		if lsn >= current {
+			return fmt.Errorf("--lsn %s must be earlier than LSN %s in state", lsn, current)
+		}
+
+		ctx, cancel := runContext(cmd.Context())
+		defer cancel()
+
+		if err := driver.CheckRewind(ctx, lsn.String()); err != nil {
+			return fmt.Errorf("can't rewind to %s: %s", lsn, err)
+		}
+
+		walState["lsn"] = lsn.String()
+
+		if err := stateStore.Save(cmd.Context(), state); err != nil {
+			return fmt.Errorf("failed to save state: %s", err)
+		}
+
+		logger.Infof("Rewound LSN from %s to %s", current, lsn)
+
+		return nil
+	},
+}
+
+// stateResyncCDCCmd forces a full resync of CDC streams e.g. once changes to rewind to are no
+// longer kept by the source. The LSN of global state is reset to 0/0 which is behind every
+// slot; the next read then recovers by loading every CDC stream in full before streaming
+var stateResyncCDCCmd = &cobra.Command{
+	Use:   "resync-cdc",
+	Short: "Load all CDC streams in full on the next read",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defer stateStore.Close()
+
+		if _, yes := bulkDriver(_driver); !yes {
+			return fmt.Errorf("%s does not keep global state", _driver.Type())
+		}
+
+		// without an LSN the next read loads CDC streams in full anyway
+		walState, current, err := globalLSN()
+		if err != nil {
+			return err
+		}
+
+		walState["lsn"] = pglogrepl.LSN(0).String()
+
+		if err := stateStore.Save(cmd.Context(), state); err != nil {
+			return fmt.Errorf("failed to save state: %s", err)
+		}
+
+		logger.Infof("Reset LSN %s of global state; CDC streams are loaded in full on the next read", current)
+
+		return nil
+	},
+}
+
+// globalLSN returns WAL state held by global state along with its LSN
+func globalLSN() (map[string]any, pglogrepl.LSN, error) {
+	if state == nil || state.Type == types.StreamType {
+		return nil, 0, fmt.Errorf("no global state found")
+	}
+
+	global, yes := state.Global.(map[string]any)
+	if !yes {
+		return nil, 0, fmt.Errorf("no global state found")
+	}
+
+	walState, yes := global["state"].(map[string]any)
+	if !yes {
+		return nil, 0, fmt.Errorf("global state holds no LSN")
+	}
+
+	current, yes := walState["lsn"].(string)
+	if !yes || current == "" {
+		return nil, 0, fmt.Errorf("global state holds no LSN")
+	}
+
+	lsn, err := pglogrepl.ParseLSN(current)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid LSN %s in state: %s", current, err)
+	}
+
+	return walState, lsn, nil
+}
+
+// configuredStream returns stream of catalog identified by id i.e. namespace.name
+func configuredStream(id string) (*types.ConfiguredStream, error) {
+	if id == "" {
+		return nil, fmt.Errorf("--stream not passed")
+	}
+
+	if catalog == nil {
+		return nil, fmt.Errorf("--catalog not passed")
+	}
+
+	i, found := utils.ArrayContains(catalog.Streams, func(elem *types.ConfiguredStream) bool {
+		return elem.ID() == id
+	})
+	if !found {
+		return nil, fmt.Errorf("stream %s not found in catalog", id)
+	}
+
+	return catalog.Streams[i], nil
+}
+
+// cursorValue parses raw as JSON falling back to a string; composite cursors take a list
+// holding a value per field. Values are validated against types of cursor fields
+func cursorValue(stream *types.ConfiguredStream, raw string) (any, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("--value not passed")
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		value = raw
+	}
+
+	fields := stream.CursorFields()
+	values := []any{value}
+	if len(fields) > 1 {
+		tuple, yes := value.([]any)
+		if !yes || len(tuple) != len(fields) {
+			return nil, fmt.Errorf("cursor of %s takes a list of values of %v", stream.ID(), fields)
+		}
+
+		values = tuple
+	}
+
+	for i, field := range fields {
+		if values[i] == nil {
+			return nil, fmt.Errorf("value of cursor field %s can not be null", field)
+		}
+
+		if stream.Schema() == nil {
+			continue
+		}
+
+		property, found := stream.Schema().Properties[field]
+		if !found {
+			return nil, fmt.Errorf("cursor field %s not found in schema of %s", field, stream.ID())
+		}
+
+		if _, err := typeutils.ReformatValueOnProperty(property, values[i]); err != nil {
+			return nil, fmt.Errorf("invalid value of cursor field %s: %s", field, err)
+		}
+	}
+
+	return value, nil
+}
+
+func init() {
+	StateCmd.AddCommand(stateShowCmd, stateResetCmd, stateSetCursorCmd, stateRewindLSNCmd, stateResyncCDCCmd)
+
+	stateResetCmd.Flags().StringVarP(&stateStream_, "stream", "", "", "(Required) Stream to reset i.e. namespace.name")
+	stateSetCursorCmd.Flags().StringVarP(&stateStream_, "stream", "", "", "(Required) Stream to set cursor of i.e. namespace.name")
+	stateSetCursorCmd.Flags().StringVarP(&cursorValue_, "value", "", "", "(Required) Cursor value; JSON list of values for composite cursors")
+	stateRewindLSNCmd.Flags().StringVarP(&lsn_, "lsn", "", "", "(Required) LSN to rewind to e.g. 0/16B3748")
+}
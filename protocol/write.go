@@ -1,12 +1,232 @@
 package protocol
 
-import "github.com/spf13/cobra"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
 
-// WriteCmd represents the read command
+	"github.com/goccy/go-json"
+
+	"github.com/gear5sh/gear5/logger"
+	"github.com/gear5sh/gear5/types"
+	"github.com/gear5sh/gear5/utils"
+	"github.com/spf13/cobra"
+)
+
+// WriteCmd represents the write command
 var WriteCmd = &cobra.Command{
 	Use:   "write",
-	Short: "write command",
+	Short: "Gear5 write command",
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if config_ == "" {
+			return fmt.Errorf("--config not passed")
+		} else {
+			if err := utils.UnmarshalFile(config_, _rawConnector.Config()); err != nil {
+				return err
+			}
+		}
+
+		if catalog_ == "" {
+			return fmt.Errorf("--catalog not passed")
+		} else {
+			catalog = &types.Catalog{}
+			if err := utils.UnmarshalFile(catalog_, catalog); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// Adapter Setup
+		err := _adapter.Setup()
+		if err != nil {
+			return err
+		}
+
+		// Create destination for every configured stream
+		streams := make(map[string]Stream)
+		for _, stream := range catalog.Streams {
+			logger.Infof("Creating stream %s", stream.ID())
+
+			if err := _adapter.Create(stream); err != nil {
+				return fmt.Errorf("failed to create stream %s: %s", stream.ID(), err)
+			}
+
+			streams[stream.ID()] = stream
+		}
+
+		numRecords := int64(0)
+		current := newSegment()
+		closed := false
+		// Adapter.Write of the current segment must return and adapter be closed on every path
+		defer func() {
+			_ = current.flush()
+			if !closed {
+				if err := closeAdapter(); err != nil {
+					logger.Error(err)
+				}
+			}
+		}()
+
+		decoder := json.NewDecoder(cmd.InOrStdin())
+		for {
+			message := types.Message{}
+			err := decoder.Decode(&message)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to decode message: %s", err)
+			}
+
+			switch message.Type {
+			case types.RecordMessage:
+				if message.Record == nil {
+					continue
+				}
+
+				id := utils.StreamIdentifier(message.Record.Stream, message.Record.Namespace)
+				if _, found := streams[id]; !found {
+					return fmt.Errorf("received record for stream %s missing from catalog", id)
+				}
+
+				if err := current.push(*message.Record); err != nil {
+					return err
+				}
+
+				numRecords++
+			case types.StateMessage:
+				if message.State == nil {
+					continue
+				}
+
+				// state can only be passed on once every record before it is durable
+				if err := current.flush(); err != nil {
+					return fmt.Errorf("error occurred while writing records: %s", err)
+				}
+
+				message.State.Mutex = &sync.Mutex{}
+				logger.LogState(message.State)
+
+				current = newSegment()
+			case types.ActionMessage:
+				if message.Action == nil {
+					continue
+				}
+
+				// records before an action are written with the schema they were read with
+				if err := current.flush(); err != nil {
+					return fmt.Errorf("error occurred while writing records: %s", err)
+				}
+
+				if err := act(streams, message.Action); err != nil {
+					return err
+				}
+
+				current = newSegment()
+			}
+		}
+
+		if err := current.flush(); err != nil {
+			return fmt.Errorf("error occurred while writing records: %s", err)
+		}
+
+		closed = true
+		if err := closeAdapter(); err != nil {
+			return err
+		}
+
+		logger.Infof("Total records written: %d", numRecords)
+
 		return nil
 	},
 }
+
+// closeAdapter closes adapter if it holds resources across writes
+func closeAdapter() error {
+	adapter, yes := _adapter.(ClosingAdapter)
+	if !yes {
+		return nil
+	}
+
+	if err := adapter.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %s", _adapter.Type(), err)
+	}
+
+	return nil
+}
+
+// act applies an action on destination if the adapter supports actions
+func act(streams map[string]Stream, action *types.ActionRow) error {
+	id := utils.StreamIdentifier(action.Stream, action.Namespace)
+	stream, found := streams[id]
+	if !found {
+		return fmt.Errorf("received action for stream %s missing from catalog", id)
+	}
+
+	adapter, yes := _adapter.(ActionAdapter)
+	if !yes {
+		logger.Warnf("%s does not support actions; skipping %s on stream %s", _adapter.Type(), action.Type, id)
+		return nil
+	}
+
+	if action.Type == types.ALTER {
+		stream.Self().AlterSchema(action.Columns)
+	}
+
+	if err := adapter.Act(stream, action); err != nil {
+		return fmt.Errorf("failed to apply %s on stream %s: %s", action.Type, id, err)
+	}
+
+	return nil
+}
+
+// segment feeds records into a single Adapter.Write call; records of a segment
+// are durable once the segment has been flushed
+type segment struct {
+	records chan types.Record
+	done    chan struct{} // closed once Adapter.Write returned
+	err     error
+	closed  bool
+}
+
+func newSegment() *segment {
+	s := &segment{
+		records: make(chan types.Record, batchSize_),
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer close(s.done)
+		s.err = _adapter.Write(s.records)
+	}()
+
+	return s
+}
+
+func (s *segment) push(record types.Record) error {
+	select {
+	case s.records <- record:
+		return nil
+	case <-s.done:
+		if s.err != nil {
+			return fmt.Errorf("error occurred while writing records: %s", s.err)
+		}
+
+		return errors.New("adapter stopped consuming records before flush")
+	}
+}
+
+// flush closes the segment and waits for the adapter to persist its records; flushing
+// again returns the same result
+func (s *segment) flush() error {
+	if !s.closed {
+		close(s.records)
+		s.closed = true
+	}
+
+	<-s.done
+	return s.err
+}
@@ -69,6 +69,7 @@ func (exec *Execution) WithRestartTimeout(timeout time.Duration) *Execution {
 	return exec
 }
 
+// Recovery logs a recovered panic; exits with failure after a panic if exit is set
 func Recovery(exit bool) {
 	err := recover()
 	if err != nil {
@@ -77,9 +78,10 @@ func Recovery(exit bool) {
 		for _, str := range strings.Split(string(debug.Stack()), "\n") {
 			logger.Error(strings.ReplaceAll(str, "\t", ""))
 		}
-	}
-	if exit {
-		os.Exit(1)
+
+		if exit {
+			os.Exit(1)
+		}
 	}
 	logger.Infof("Time of execution %v", time.Since(startTime).String())
 }
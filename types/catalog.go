@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gear5sh/gear5/utils"
+	"github.com/goccy/go-json"
 )
 
 // Message is a dto for gear5 output row representation
@@ -19,12 +20,13 @@ type Message struct {
 	Spec             map[string]interface{} `json:"spec,omitempty"`
 }
 
+// ActionRow is a dto for schema changes to be applied on destination of a stream
 type ActionRow struct {
-	// Type Action `json:"type"`
-	// Add alter
-	// add create
-	// add drop
-	// add truncate
+	Type      Action `json:"type"`
+	Namespace string `json:"namespace,omitempty"`
+	Stream    string `json:"stream,omitempty"`
+	// Columns added or changed; set with ALTER
+	Columns map[string]*Property `json:"columns,omitempty"`
 }
 
 // Log is a dto for airbyte logs serialization
@@ -42,7 +44,11 @@ type StatusRow struct {
 // Record is a dto for airbyte record serialization
 type Record struct {
 	// close is used to stop iterating records
-	Close     bool                   `json:"-"`
+	Close bool `json:"-"`
+	// checkpoint is called once records sent before it are emitted; followed by a state message
+	Checkpoint func() `json:"-"`
+	// action is emitted in order with records in place of the record
+	Action    *ActionRow             `json:"-"`
 	Namespace string                 `json:"namespace,omitempty"`
 	Stream    string                 `json:"stream,omitempty"`
 	Data      map[string]interface{} `json:"data,omitempty"`
@@ -54,6 +60,15 @@ type Catalog struct {
 	Streams []*ConfiguredStream `json:"streams,omitempty"`
 }
 
+// MarshalJSON encodes catalog while no schema of its streams is being swapped
+func (c *Catalog) MarshalJSON() ([]byte, error) {
+	schemaMutex.RLock()
+	defer schemaMutex.RUnlock()
+
+	type Alias Catalog
+	return json.Marshal((*Alias)(c))
+}
+
 // Schema is a dto for Airbyte catalog Schema object serialization
 type TypeSchema struct {
 	Properties map[string]*Property `json:"properties,omitempty"`
@@ -68,9 +83,46 @@ func (t *TypeSchema) GetType(column string) (DataType, error) {
 	return p.DataType(), nil
 }
 
+// Diff returns columns of schema that are missing or typed differently in other
+func (t *TypeSchema) Diff(other *TypeSchema) map[string]*Property {
+	diff := make(map[string]*Property)
+	for column, property := range t.Properties {
+		if other != nil {
+			if existing, found := other.Properties[column]; found && existing.Equal(property) {
+				continue
+			}
+		}
+
+		diff[column] = property
+	}
+
+	return diff
+}
+
+// Without returns a copy of schema without columns
+func (t *TypeSchema) Without(columns ...string) *TypeSchema {
+	if t == nil {
+		return nil
+	}
+
+	schema := &TypeSchema{Properties: make(map[string]*Property)}
+	for column, property := range t.Properties {
+		if !utils.ExistInArray(columns, column) {
+			schema.Properties[column] = property
+		}
+	}
+
+	return schema
+}
+
 // Property is a dto for catalog properties representation
 type Property struct {
 	Type []DataType `json:"type,omitempty"`
+	// Precision and Scale of DECIMAL; unset if unconstrained
+	Precision *int `json:"precision,omitempty"`
+	Scale     *int `json:"scale,omitempty"`
+	// Items is type of ARRAY elements; unset if unknown
+	Items *Property `json:"items,omitempty"`
 	// TODO: Decide to keep in the Protocol Or Not
 	// Format string     `json:"format,omitempty"`
 }
@@ -86,6 +138,36 @@ func (p *Property) DataType() DataType {
 	return p.Type[i]
 }
 
+func (p *Property) Equal(other *Property) bool {
+	if len(p.Type) != len(other.Type) {
+		return false
+	}
+
+	for _, typ := range p.Type {
+		if !utils.ExistInArray(other.Type, typ) {
+			return false
+		}
+	}
+
+	if !equalInt(p.Precision, other.Precision) || !equalInt(p.Scale, other.Scale) {
+		return false
+	}
+
+	if p.Items == nil || other.Items == nil {
+		return p.Items == other.Items
+	}
+
+	return p.Items.Equal(other.Items)
+}
+
+func equalInt(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
 func (p *Property) Nullable() bool {
 	_, found := utils.ArrayContains(p.Type, func(elem DataType) bool {
 		return elem == NULL
@@ -12,6 +12,10 @@ const (
 	ARRAY     DataType = "array"
 	UNKNOWN   DataType = "unknown"
 	TIMESTAMP DataType = "timestamp"
+	DECIMAL   DataType = "decimal" // exact numbers kept as strings; precision and scale are set on property
+	DATE      DataType = "date"
+	TIME      DataType = "time" // time of day
+	JSON      DataType = "json"
 )
 
 type RecordData = map[string]any
@@ -0,0 +1,350 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Filter is a parsed row predicate of a configured stream e.g.
+//
+//	tenant_id = 42 AND (status != 'deleted' OR deleted_at IS NULL)
+//
+// Conditions compare a column with literals through =, !=, <>, <, <=, >, >=, IN,
+// NOT IN, IS NULL and IS NOT NULL and are combined with AND, OR and parentheses
+type Filter struct {
+	// AND or OR of Operands; empty for a condition
+	Operator string
+	Operands []*Filter
+
+	Column     string
+	Comparison string // comparison of a condition e.g. =, IN, IS NULL
+	Values     []any  // literals of a condition; string, int64, float64 or bool
+}
+
+// ParseFilter parses a filter expression
+func ParseFilter(expression string) (*Filter, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &filterParser{tokens: tokens}
+	filter, err := parser.or()
+	if err != nil {
+		return nil, err
+	}
+
+	if !parser.done() {
+		return nil, fmt.Errorf("unexpected %s in filter", parser.peek().text)
+	}
+
+	return filter, nil
+}
+
+// Columns returns columns referred by filter
+func (f *Filter) Columns() []string {
+	if f.Operator == "" {
+		return []string{f.Column}
+	}
+
+	columns := []string{}
+	for _, operand := range f.Operands {
+		columns = append(columns, operand.Columns()...)
+	}
+
+	return columns
+}
+
+// SQL returns filter as a SQL predicate; literals are bound through placeholder as text
+func (f *Filter) SQL(placeholder func(value any) string) string {
+	if f.Operator != "" {
+		predicates := []string{}
+		for _, operand := range f.Operands {
+			predicates = append(predicates, operand.SQL(placeholder))
+		}
+
+		return fmt.Sprintf("(%s)", strings.Join(predicates, fmt.Sprintf(" %s ", f.Operator)))
+	}
+
+	column := fmt.Sprintf(`"%s"`, strings.ReplaceAll(f.Column, `"`, `""`))
+	switch f.Comparison {
+	case "IS NULL", "IS NOT NULL":
+		return fmt.Sprintf("%s %s", column, f.Comparison)
+	case "IN", "NOT IN":
+		placeholders := []string{}
+		for _, value := range f.Values {
+			placeholders = append(placeholders, placeholder(fmt.Sprint(value)))
+		}
+
+		return fmt.Sprintf("%s %s (%s)", column, f.Comparison, strings.Join(placeholders, ", "))
+	default:
+		return fmt.Sprintf("%s %s %s", column, f.Comparison, placeholder(fmt.Sprint(f.Values[0])))
+	}
+}
+
+type tokenKind int
+
+const (
+	identifierToken tokenKind = iota
+	literalToken
+	symbolToken
+)
+
+type token struct {
+	kind  tokenKind
+	text  string // keywords and symbols are upper cased
+	value any    // value of a literal
+}
+
+func tokenize(expression string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, token{kind: symbolToken, text: string(r)})
+			i++
+		case strings.ContainsRune("=!<>", r):
+			j := i + 1
+			if j < len(runes) && strings.ContainsRune("=>", runes[j]) {
+				j++
+			}
+
+			symbol := string(runes[i:j])
+			switch symbol {
+			case "=", "!=", "<>", "<", "<=", ">", ">=":
+			default:
+				return nil, fmt.Errorf("invalid operator %s in filter", symbol)
+			}
+
+			tokens = append(tokens, token{kind: symbolToken, text: symbol})
+			i = j
+		case r == '\'' || r == '"':
+			// quotes are escaped by doubling
+			text := strings.Builder{}
+			j := i + 1
+			for ; j < len(runes); j++ {
+				if runes[j] == r {
+					if j+1 < len(runes) && runes[j+1] == r {
+						text.WriteRune(r)
+						j++
+						continue
+					}
+					break
+				}
+				text.WriteRune(runes[j])
+			}
+
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated quote in filter")
+			}
+
+			if r == '\'' {
+				tokens = append(tokens, token{kind: literalToken, text: text.String(), value: text.String()})
+			} else {
+				tokens = append(tokens, token{kind: identifierToken, text: text.String()})
+			}
+			i = j + 1
+		case unicode.IsDigit(r) || r == '-' || r == '.':
+			j := i + 1
+			for ; j < len(runes); j++ {
+				c := runes[j]
+				// exponents may be signed e.g. 1e-5
+				exponentSign := (c == '+' || c == '-') && (runes[j-1] == 'e' || runes[j-1] == 'E')
+				if !unicode.IsDigit(c) && c != '.' && c != 'e' && c != 'E' && !exponentSign {
+					break
+				}
+			}
+
+			text := string(runes[i:j])
+			if value, err := strconv.ParseInt(text, 10, 64); err == nil {
+				tokens = append(tokens, token{kind: literalToken, text: text, value: value})
+			} else if value, err := strconv.ParseFloat(text, 64); err == nil {
+				tokens = append(tokens, token{kind: literalToken, text: text, value: value})
+			} else {
+				return nil, fmt.Errorf("invalid number %s in filter", text)
+			}
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+
+			word := string(runes[i:j])
+			switch upper := strings.ToUpper(word); upper {
+			case "AND", "OR", "NOT", "IN", "IS", "NULL":
+				tokens = append(tokens, token{kind: symbolToken, text: upper})
+			case "TRUE", "FALSE":
+				tokens = append(tokens, token{kind: literalToken, text: upper, value: upper == "TRUE"})
+			default:
+				tokens = append(tokens, token{kind: identifierToken, text: word})
+			}
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q in filter", r)
+		}
+	}
+
+	return tokens, nil
+}
+
+// filterParser is a recursive descent parser; AND binds tighter than OR
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() token {
+	if p.done() {
+		return token{kind: symbolToken, text: "end of filter"}
+	}
+
+	return p.tokens[p.pos]
+}
+
+// accept consumes next token if it is the symbol
+func (p *filterParser) accept(symbol string) bool {
+	if next := p.peek(); !p.done() && next.kind == symbolToken && next.text == symbol {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *filterParser) expect(symbol string) error {
+	if !p.accept(symbol) {
+		return fmt.Errorf("expected %s but found %s in filter", symbol, p.peek().text)
+	}
+
+	return nil
+}
+
+func (p *filterParser) or() (*Filter, error) {
+	return p.combine("OR", p.and)
+}
+
+func (p *filterParser) and() (*Filter, error) {
+	return p.combine("AND", p.term)
+}
+
+func (p *filterParser) combine(operator string, operand func() (*Filter, error)) (*Filter, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []*Filter{first}
+	for p.accept(operator) {
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		operands = append(operands, next)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+
+	return &Filter{Operator: operator, Operands: operands}, nil
+}
+
+func (p *filterParser) term() (*Filter, error) {
+	if p.accept("(") {
+		filter, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+
+		return filter, p.expect(")")
+	}
+
+	column := p.peek()
+	if p.done() || column.kind != identifierToken {
+		return nil, fmt.Errorf("expected column but found %s in filter", column.text)
+	}
+	p.pos++
+
+	condition := &Filter{Column: column.text}
+	switch {
+	case p.accept("IS"):
+		condition.Comparison = "IS NULL"
+		if p.accept("NOT") {
+			condition.Comparison = "IS NOT NULL"
+		}
+
+		return condition, p.expect("NULL")
+	case p.accept("NOT"):
+		condition.Comparison = "NOT IN"
+		if err := p.expect("IN"); err != nil {
+			return nil, err
+		}
+
+		return condition, p.list(condition)
+	case p.accept("IN"):
+		condition.Comparison = "IN"
+		return condition, p.list(condition)
+	}
+
+	comparison := p.peek()
+	switch comparison.text {
+	case "=", "!=", "<>", "<", "<=", ">", ">=":
+	default:
+		return nil, fmt.Errorf("expected comparison after %s but found %s in filter", column.text, comparison.text)
+	}
+	p.pos++
+
+	condition.Comparison = comparison.text
+	if condition.Comparison == "<>" {
+		condition.Comparison = "!="
+	}
+
+	value, err := p.literal()
+	if err != nil {
+		return nil, err
+	}
+	condition.Values = []any{value}
+
+	return condition, nil
+}
+
+// list parses parenthesized literals of IN
+func (p *filterParser) list(condition *Filter) error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+
+	for {
+		value, err := p.literal()
+		if err != nil {
+			return err
+		}
+		condition.Values = append(condition.Values, value)
+
+		if !p.accept(",") {
+			return p.expect(")")
+		}
+	}
+}
+
+func (p *filterParser) literal() (any, error) {
+	next := p.peek()
+	if p.done() || next.kind != literalToken {
+		return nil, fmt.Errorf("expected value but found %s in filter", next.text)
+	}
+	p.pos++
+
+	return next.value, nil
+}
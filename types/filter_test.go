@@ -0,0 +1,58 @@
+package types_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gear5sh/gear5/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sql returns SQL of filter with numbered placeholders and values bound to them
+func sql(filter *types.Filter) (string, []any) {
+	args := []any{}
+	sql := filter.SQL(func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	})
+
+	return sql, args
+}
+
+func TestFilterSQL(t *testing.T) {
+	filter, err := types.ParseFilter(`tenant_id = 42 AND (status <> 'it''s' OR "Deleted At" IS NULL) and kind not in ('a', 'b')`)
+	require.NoError(t, err)
+
+	query, args := sql(filter)
+	assert.Equal(t, `("tenant_id" = $1 AND ("status" != $2 OR "Deleted At" IS NULL) AND "kind" NOT IN ($3, $4))`, query)
+	assert.Equal(t, []any{"42", "it's", "a", "b"}, args)
+	assert.ElementsMatch(t, []string{"tenant_id", "status", "Deleted At", "kind"}, filter.Columns())
+}
+
+func TestFilterSignedExponents(t *testing.T) {
+	filter, err := types.ParseFilter("amount > 1e-5 AND amount < 2E+3 AND rate = 1.5e2 AND delta >= -3")
+	require.NoError(t, err)
+
+	_, args := sql(filter)
+	assert.Equal(t, []any{"1e-05", "2000", "150", "-3"}, args)
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"tenant_id",
+		"tenant_id = ",
+		"tenant_id == 1",
+		"tenant_id = 'open",
+		"(tenant_id = 1",
+		"tenant_id = 1 AND",
+		"tenant_id IN 1",
+		"tenant_id = 1; DROP TABLE users",
+		"amount > 1e-",
+		"amount > 1-5",
+	} {
+		_, err := types.ParseFilter(expression)
+		assert.Error(t, err, expression)
+	}
+}
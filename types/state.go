@@ -19,13 +19,16 @@ const (
 	MixedType StateType = "MIXED"
 )
 
-// TODO: Add validation tags; Write custom unmarshal that triggers validation
-// State is a dto for airbyte state serialization
+// State is a dto for airbyte state serialization; documents are validated against
+// StateSchema and migrated to StateVersion by ParseState
 type State struct {
 	*sync.Mutex `json:"-"`
-	Type        StateType      `json:"type"`
-	Global      any            `json:"global,omitempty"`
-	Streams     []*StreamState `json:"streams,omitempty"`
+	// Version of state document; documents without version are of version 0
+	Version int `json:"version"`
+	// @jsonSchema(required=true)
+	Type    StateType      `json:"type"`
+	Global  any            `json:"global,omitempty"`
+	Streams []*StreamState `json:"streams,omitempty"`
 }
 
 var (
@@ -62,6 +65,7 @@ func (s *State) IsZero() bool {
 }
 
 type StreamState struct {
+	// @jsonSchema(required=true, minLength=1)
 	Stream    string `json:"stream"`
 	Namespace string `json:"namespace"`
 	// State contains the sync's Cursor field and the latest cursor values
@@ -0,0 +1,35 @@
+//go:build schema
+
+package types_test
+
+import (
+	stdjson "encoding/json"
+	"os"
+	"testing"
+
+	"github.com/gear5sh/gear5/jsonschema"
+	"github.com/gear5sh/gear5/logger/console"
+	"github.com/gear5sh/gear5/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schema is generated from source of types by the jsonschema package; as loading source
+// takes long this runs only with the schema build tag e.g. by go generate ./types/
+func TestStateSchemaGenerated(t *testing.T) {
+	console.SetupWriter(os.Stdout, os.Stderr)
+
+	generated, err := jsonschema.Reflect(types.State{})
+	require.NoError(t, err)
+
+	// go-json can't encode generated schemas
+	encoded, err := stdjson.MarshalIndent(generated, "", "\t")
+	require.NoError(t, err)
+	encoded = append(encoded, '\n')
+
+	if os.Getenv("UPDATE_STATE_SCHEMA") != "" {
+		require.NoError(t, os.WriteFile("state_schema.json", encoded, 0o644))
+	}
+
+	assert.Equal(t, string(encoded), string(types.StateSchema))
+}
@@ -0,0 +1,91 @@
+package types
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/gear5sh/gear5/jsonschema/schema"
+	"github.com/goccy/go-json"
+)
+
+// StateSchema is the JSON schema of state documents generated from State by the
+// jsonschema package; regenerate with go generate ./types/ and check it is current
+// with go test -tags schema ./types/
+//
+//go:generate env UPDATE_STATE_SCHEMA=1 go test -tags schema -run TestStateSchemaGenerated .
+//go:embed state_schema.json
+var StateSchema []byte
+
+// StateVersion is the version of state documents written by this release
+const StateVersion = 1
+
+// stateMigrations upgrade state documents of the version of their index to the next one
+var stateMigrations = []func(state *State) error{
+	// documents of version 0 predate versioning and are otherwise unchanged
+	func(state *State) error { return nil },
+}
+
+// ParseState validates a state document against StateSchema and migrates it to StateVersion
+func ParseState(data []byte) (*State, error) {
+	if err := schema.Validate(StateSchema, data); err != nil {
+		return nil, fmt.Errorf("invalid state: %s", err)
+	}
+
+	state := &State{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse state: %s", err)
+	}
+
+	switch state.Type {
+	case GlobalType, StreamType, MixedType:
+	default:
+		return nil, fmt.Errorf("invalid state type %s; valid are %s, %s, %s", state.Type, GlobalType, StreamType, MixedType)
+	}
+
+	if err := state.Migrate(); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Migrate upgrades state to StateVersion
+func (s *State) Migrate() error {
+	if s.Version > StateVersion {
+		return fmt.Errorf("state version %d is newer than supported version %d", s.Version, StateVersion)
+	}
+
+	for s.Version < StateVersion {
+		if err := stateMigrations[s.Version](s); err != nil {
+			return fmt.Errorf("failed to migrate state from version %d: %s", s.Version, err)
+		}
+
+		s.Version++
+	}
+
+	return nil
+}
+
+// MigrateType converts state kept for a state type to another so that the sync method of a
+// connection can be changed without a full resync e.g. STREAM state of incremental syncs to
+// MIXED state of CDC. Cursors of streams are kept while global state of the previous
+// method is dropped to be set up by the driver
+func (s *State) MigrateType(to StateType) error {
+	switch {
+	case s.Type == to:
+		return nil
+	case to == StreamType:
+		s.Global = nil
+	case to == MixedType && s.Type == StreamType:
+		s.Global = nil
+	case to == MixedType && s.Type == GlobalType:
+		// global state is kept; streams are added by the driver
+	case to == GlobalType && s.Type == MixedType:
+		s.Streams = nil
+	default:
+		return fmt.Errorf("state of type %s can't be migrated to %s", s.Type, to)
+	}
+
+	s.Type = to
+	return nil
+}
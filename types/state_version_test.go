@@ -0,0 +1,49 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/gear5sh/gear5/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseState(t *testing.T) {
+	state, err := types.ParseState([]byte(`{"type": "STREAM", "streams": [{"stream": "users", "namespace": "public", "state": {"updated_at": "2024-01-01T00:00:00Z"}}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, types.StateVersion, state.Version)
+	assert.Equal(t, "2024-01-01T00:00:00Z", state.Streams[0].State["updated_at"])
+
+	state, err = types.ParseState([]byte(`{"version": 1, "type": "MIXED", "global": null, "streams": [{"stream": "users", "state": null}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, types.MixedType, state.Type)
+
+	for _, document := range []string{
+		`{"streams": []}`,
+		`{"type": "STREAMS"}`,
+		`{"type": "STREAM", "version": "1"}`,
+		`{"type": "STREAM", "version": 2}`,
+		`{"type": "STREAM", "streams": {"stream": "users"}}`,
+		`{"type": "STREAM", "streams": [{"namespace": "public"}]}`,
+		`{"type": "STREAM", "streams": [{"stream": "users", "state": []}]}`,
+	} {
+		_, err := types.ParseState([]byte(document))
+		assert.Error(t, err, document)
+	}
+}
+
+func TestMigrateStateType(t *testing.T) {
+	streams := []*types.StreamState{{Stream: "users", Namespace: "public", State: map[string]interface{}{"id": 42}}}
+
+	state := &types.State{Type: types.StreamType, Streams: streams}
+	require.NoError(t, state.MigrateType(types.MixedType))
+	assert.Equal(t, types.MixedType, state.Type)
+	assert.Equal(t, streams, state.Streams)
+
+	state.Global = map[string]interface{}{"lsn": "0/16B3748"}
+	require.NoError(t, state.MigrateType(types.StreamType))
+	assert.Nil(t, state.Global)
+	assert.Equal(t, streams, state.Streams)
+
+	assert.Error(t, state.MigrateType(types.GlobalType))
+}
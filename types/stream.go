@@ -61,16 +61,19 @@ func (s *Stream) WithCursorField(columns ...string) *Stream {
 
 // Add or Update Column in Stream Type Schema
 func (s *Stream) UpsertField(column string, typ DataType, nullable bool) {
+	s.UpsertProperty(column, &Property{
+		Type: []DataType{typ},
+	}, nullable)
+}
+
+// UpsertProperty sets property of column e.g. with precision of decimals or array items
+func (s *Stream) UpsertProperty(column string, property *Property, nullable bool) {
 	if s.Schema == nil {
 		s.Schema = &TypeSchema{
 			Properties: map[string]*Property{},
 		}
 	}
 
-	property := &Property{
-		Type: []DataType{typ},
-	}
-
 	// if typ == TIMESTAMP {
 	// 	property.Format = "date-time"
 	// }
@@ -2,6 +2,8 @@ package types
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/gear5sh/gear5/utils"
 )
@@ -13,13 +15,16 @@ type ConfiguredStream struct {
 	// Column that's being used as cursor; MUST NOT BE mutated
 	//
 	// Cursor field is used in Incremental and in Mixed type GroupRead where connector uses
-	// this field as recovery column incase of some inconsistencies
+	// this field as recovery column incase of some inconsistencies; composite cursors are
+	// comma separated e.g. updated_at,id
 	CursorField    string       `json:"cursor_field,omitempty"`
-	ExcludeColumns []string     `json:"exclude_columns,omitempty"` // TODO: Implement excluding columns from fetching
+	ExcludeColumns []string     `json:"exclude_columns,omitempty"` // Columns neither read nor written
+	Filter         string       `json:"filter,omitempty"`          // Predicate of rows to read e.g. tenant_id = 42; CDC deletes rows updated out of it
 	CursorValue    any          `json:"-"`                         // Cached initial state value
 	batchSize      int          `json:"-"`                         // Batch size for syncing data
 	state          *StreamState `json:"-"`                         // in-memory state copy for individual stream
 	connectorState *State       `json:"-"`                         // in-memory pointer to central state
+	filter         *Filter      `json:"-"`                         // parsed Filter
 
 	// DestinationSyncMode string   `json:"destination_sync_mode,omitempty"`
 }
@@ -44,10 +49,36 @@ func (s *ConfiguredStream) Namespace() string {
 	return s.Stream.Namespace
 }
 
+// schemaMutex guards schemas of streams swapped by AlterSchema while being read
+var schemaMutex sync.RWMutex
+
 func (s *ConfiguredStream) Schema() *TypeSchema {
+	schemaMutex.RLock()
+	defer schemaMutex.RUnlock()
+
 	return s.Stream.Schema
 }
 
+// AlterSchema swaps schema for a copy holding properties in addition; schemas are never
+// changed in place since they are read e.g. by catalogs logged concurrently
+func (s *ConfiguredStream) AlterSchema(properties map[string]*Property) {
+	schemaMutex.Lock()
+	defer schemaMutex.Unlock()
+
+	altered := &TypeSchema{Properties: make(map[string]*Property)}
+	if s.Stream.Schema != nil {
+		for column, property := range s.Stream.Schema.Properties {
+			altered.Properties[column] = property
+		}
+	}
+
+	for column, property := range properties {
+		altered.Properties[column] = property
+	}
+
+	s.Stream.Schema = altered
+}
+
 func (s *ConfiguredStream) SupportedSyncModes() *Set[SyncMode] {
 	return s.Stream.SupportedSyncModes
 }
@@ -60,6 +91,24 @@ func (s *ConfiguredStream) Cursor() string {
 	return s.CursorField
 }
 
+// CursorFields returns fields of a composite cursor in order; empty without cursor
+func (s *ConfiguredStream) CursorFields() []string {
+	if s.CursorField == "" {
+		return nil
+	}
+
+	return splitCursor(s.CursorField)
+}
+
+func splitCursor(cursor string) []string {
+	fields := strings.Split(cursor, ",")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+
+	return fields
+}
+
 // Returns empty and missing
 func (s *ConfiguredStream) SetupState(state *State, batchSize int) error {
 	s.SetBatchSize(batchSize)
@@ -70,13 +119,22 @@ func (s *ConfiguredStream) SetupState(state *State, batchSize int) error {
 			return elem.Namespace == s.Namespace() && elem.Stream == s.Name()
 		})
 		if contains {
-			value, found := state.Streams[i].State[s.CursorField]
+			s.state = state.Streams[i]
+			if s.state.State == nil {
+				s.state.State = make(map[string]any)
+			}
+
+			// streams without cursor only keep progress in state
+			if s.CursorField == "" {
+				return nil
+			}
+
+			value, found := s.state.State[s.CursorField]
 			if !found {
 				return ErrStateCursorMissing
 			}
 
 			s.CursorValue = value
-			s.state = state.Streams[i]
 
 			return nil
 		}
@@ -92,15 +150,28 @@ func (s *ConfiguredStream) InitialState() any {
 }
 
 func (s *ConfiguredStream) SetState(value any) {
+	s.SetStateKey(s.Cursor(), value)
+}
+
+func (s *ConfiguredStream) GetState() any {
+	return s.GetStateKey(s.Cursor())
+}
+
+// SetStateKey sets a value in stream state; nil value removes the key
+func (s *ConfiguredStream) SetStateKey(key string, value any) {
 	s.connectorState.Lock()
 	defer s.connectorState.Unlock()
 
 	if s.state == nil {
+		if value == nil {
+			return
+		}
+
 		ss := &StreamState{
 			Stream:    s.Name(),
 			Namespace: s.Namespace(),
 			State: map[string]any{
-				s.Cursor(): value,
+				key: value,
 			},
 		}
 
@@ -110,17 +181,43 @@ func (s *ConfiguredStream) SetState(value any) {
 		return
 	}
 
-	s.state.State[s.Cursor()] = value
+	if value == nil {
+		delete(s.state.State, key)
+		return
+	}
+
+	s.state.State[key] = value
 }
 
-func (s *ConfiguredStream) GetState() any {
+func (s *ConfiguredStream) GetStateKey(key string) any {
 	s.connectorState.Lock()
 	defer s.connectorState.Unlock()
 
 	if s.state == nil || s.state.State == nil {
 		return nil
 	}
-	return s.state.State[s.Cursor()]
+	return s.state.State[key]
+}
+
+// HasState returns true if any value is kept in stream state
+func (s *ConfiguredStream) HasState() bool {
+	if s.connectorState == nil {
+		return false
+	}
+
+	s.connectorState.Lock()
+	defer s.connectorState.Unlock()
+
+	return s.state != nil && len(s.state.State) > 0
+}
+
+// RowFilter returns parsed filter of stream; nil if rows aren't filtered
+func (s *ConfiguredStream) RowFilter() (*Filter, error) {
+	if s.Filter == "" || s.filter != nil {
+		return s.filter, nil
+	}
+
+	return ParseFilter(s.Filter)
 }
 
 func (s *ConfiguredStream) BatchSize() int {
@@ -137,13 +234,52 @@ func (s *ConfiguredStream) Validate(source *Stream) error {
 		return fmt.Errorf("invalid sync mode[%s]; valid are %v", s.SyncMode, source.SupportedSyncModes)
 	}
 
-	if !source.DefaultCursorFields.Exists(s.CursorField) {
-		return fmt.Errorf("invalid cursor field [%s]; valid are %v", s.CursorField, source.DefaultCursorFields)
+	for _, field := range splitCursor(s.CursorField) {
+		if !source.DefaultCursorFields.Exists(field) {
+			return fmt.Errorf("invalid cursor field [%s]; valid are %v", field, source.DefaultCursorFields)
+		}
 	}
 
 	if source.SourceDefinedPrimaryKey.ProperSubsetOf(s.Stream.SourceDefinedPrimaryKey) {
 		return fmt.Errorf("differnce found with primary keys: %v", source.SourceDefinedPrimaryKey.Difference(s.Stream.SourceDefinedPrimaryKey).Array())
 	}
 
+	for _, column := range s.ExcludeColumns {
+		if utils.ExistInArray(s.CursorFields(), column) {
+			return fmt.Errorf("cursor field [%s] can not be excluded", column)
+		}
+
+		if source.SourceDefinedPrimaryKey.Exists(column) {
+			return fmt.Errorf("primary key [%s] can not be excluded", column)
+		}
+
+		if source.Schema != nil {
+			if _, found := source.Schema.Properties[column]; !found {
+				return fmt.Errorf("excluded column [%s] not found in source", column)
+			}
+		}
+	}
+
+	if s.Filter != "" {
+		filter, err := ParseFilter(s.Filter)
+		if err != nil {
+			return fmt.Errorf("invalid filter: %s", err)
+		}
+
+		for _, column := range filter.Columns() {
+			if utils.ExistInArray(s.ExcludeColumns, column) {
+				return fmt.Errorf("filter column [%s] is excluded", column)
+			}
+
+			if source.Schema != nil {
+				if _, found := source.Schema.Properties[column]; !found {
+					return fmt.Errorf("filter column [%s] not found in source", column)
+				}
+			}
+		}
+
+		s.filter = filter
+	}
+
 	return nil
 }
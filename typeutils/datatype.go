@@ -1,11 +1,13 @@
 package typeutils
 
 import (
+	"cmp"
 	"fmt"
+	"math/big"
 	"reflect"
+	"strings"
 
 	"github.com/gear5sh/gear5/types"
-	"github.com/gear5sh/gear5/utils"
 )
 
 // TypeFromValue return DataType from v type
@@ -41,38 +43,144 @@ func TypeFromValue(v interface{}) types.DataType {
 
 func MaximumOnDataType[T any](typ types.DataType, a, b T) (T, error) {
 	switch typ {
-	case types.TIMESTAMP:
+	case types.TIMESTAMP, types.DATE, types.INT64, types.FLOAT64, types.DECIMAL, types.STRING:
+	default:
+		return a, fmt.Errorf("comparison not available for data types %v now", typ)
+	}
+
+	result, err := Compare(typ, a, b)
+	if err != nil {
+		return a, err
+	}
+
+	if result > 0 {
+		return a, nil
+	}
+
+	return b, nil
+}
+
+// MaximumCursor returns the greater of cursor values; values of composite cursors are
+// lists compared field by field on types of their fields
+func MaximumCursor(datatypes []types.DataType, a, b any) (any, error) {
+	if len(datatypes) == 1 {
+		return MaximumOnDataType(datatypes[0], a, b)
+	}
+
+	result, err := CompareTuple(datatypes, a, b)
+	if err != nil {
+		return a, err
+	}
+
+	if result > 0 {
+		return a, nil
+	}
+
+	return b, nil
+}
+
+// CompareTuple compares lists of values in order like SQL row comparison
+func CompareTuple(datatypes []types.DataType, a, b any) (int, error) {
+	atuple, aok := a.([]any)
+	btuple, bok := b.([]any)
+	if !aok || !bok || len(atuple) != len(datatypes) || len(btuple) != len(datatypes) {
+		return 0, fmt.Errorf("expected values of %d fields while comparing %v and %v", len(datatypes), a, b)
+	}
+
+	for i, datatype := range datatypes {
+		result, err := Compare(datatype, atuple[i], btuple[i])
+		if err != nil || result != 0 {
+			return result, err
+		}
+	}
+
+	return 0, nil
+}
+
+// Compare returns -1, 0 or 1 as a is less than, equal to or greater than b once both are
+// reformatted to typ; values of other types are compared as strings
+func Compare(typ types.DataType, a, b any) (int, error) {
+	switch typ {
+	case types.TIMESTAMP, types.DATE:
 		adate, err := ReformatDate(a)
 		if err != nil {
-			return a, fmt.Errorf("failed to reformat[%v] while comparing: %s", a, err)
+			return 0, fmt.Errorf("failed to reformat[%v] while comparing: %s", a, err)
 		}
 		bdate, err := ReformatDate(b)
 		if err != nil {
-			return a, fmt.Errorf("failed to reformat[%v] while comparing: %s", b, err)
+			return 0, fmt.Errorf("failed to reformat[%v] while comparing: %s", b, err)
 		}
 
-		if utils.MaxDate(adate, bdate) == adate {
-			return a, nil
-		}
-
-		return b, nil
+		return adate.Compare(bdate), nil
 	case types.INT64:
 		aint, err := ReformatInt64(a)
 		if err != nil {
-			return a, fmt.Errorf("failed to reformat[%v] while comparing: %s", a, err)
+			return 0, fmt.Errorf("failed to reformat[%v] while comparing: %s", a, err)
 		}
-
 		bint, err := ReformatInt64(b)
 		if err != nil {
-			return a, fmt.Errorf("failed to reformat[%v] while comparing: %s", b, err)
+			return 0, fmt.Errorf("failed to reformat[%v] while comparing: %s", b, err)
 		}
 
-		if aint > bint {
-			return a, nil
+		return cmp.Compare(aint, bint), nil
+	case types.FLOAT64:
+		afloat, err := ReformatFloat64(a)
+		if err != nil {
+			return 0, fmt.Errorf("failed to reformat[%v] while comparing: %s", a, err)
+		}
+		bfloat, err := ReformatFloat64(b)
+		if err != nil {
+			return 0, fmt.Errorf("failed to reformat[%v] while comparing: %s", b, err)
 		}
 
-		return b, nil
+		return cmp.Compare(afloat.(float64), bfloat.(float64)), nil
+	case types.DECIMAL:
+		arat, err := toRat(a)
+		if err != nil {
+			return 0, err
+		}
+		brat, err := toRat(b)
+		if err != nil {
+			return 0, err
+		}
+
+		return arat.Cmp(brat), nil
+	case types.BOOL:
+		abool, err := ReformatValue(types.BOOL, a)
+		if err != nil {
+			return 0, err
+		}
+		bbool, err := ReformatValue(types.BOOL, b)
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case abool == bbool:
+			return 0, nil
+		case bbool.(bool):
+			return -1, nil
+		default:
+			return 1, nil
+		}
 	default:
-		return a, fmt.Errorf("comparison not available for data types %v now", typ)
+		astring, _ := ReformatValue(types.STRING, a)
+		bstring, _ := ReformatValue(types.STRING, b)
+
+		return strings.Compare(astring.(string), bstring.(string)), nil
 	}
 }
+
+func toRat(v any) (*big.Rat, error) {
+	decimal, err := ReformatDecimal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reformat[%v] while comparing: %s", v, err)
+	}
+
+	rat, ok := new(big.Rat).SetString(decimal)
+	if !ok {
+		return nil, fmt.Errorf("decimal %s is not comparable", decimal)
+	}
+
+	return rat, nil
+}
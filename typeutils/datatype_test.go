@@ -0,0 +1,39 @@
+package typeutils
+
+import (
+	"testing"
+
+	"github.com/gear5sh/gear5/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaximumCursor(t *testing.T) {
+	tests := []struct {
+		name      string
+		datatypes []types.DataType
+		a, b      any
+		maximum   any
+	}{
+		{"float", []types.DataType{types.FLOAT64}, 1.5, float64(2), float64(2)},
+		{"uuidv7", []types.DataType{types.STRING}, "018f6f1c-7b1a-7000-8000-000000000002", "018f6f1c-7b19-7000-8000-000000000001", "018f6f1c-7b1a-7000-8000-000000000002"},
+		{"timestamp tie", []types.DataType{types.TIMESTAMP, types.INT64}, []any{"2024-01-01T00:00:00Z", int64(7)}, []any{"2024-01-01T00:00:00Z", float64(9)}, []any{"2024-01-01T00:00:00Z", float64(9)}},
+		{"timestamp first", []types.DataType{types.TIMESTAMP, types.INT64}, []any{"2024-01-02T00:00:00Z", int64(1)}, []any{"2024-01-01T00:00:00Z", int64(9)}, []any{"2024-01-02T00:00:00Z", int64(1)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			maximum, err := MaximumCursor(test.datatypes, test.a, test.b)
+			require.NoError(t, err)
+			assert.Equal(t, test.maximum, maximum)
+		})
+	}
+}
+
+func TestMaximumCursorMismatch(t *testing.T) {
+	_, err := MaximumCursor([]types.DataType{types.TIMESTAMP, types.INT64}, []any{"2024-01-01T00:00:00Z"}, []any{"2024-01-01T00:00:00Z", int64(1)})
+	assert.Error(t, err)
+
+	_, err = MaximumCursor([]types.DataType{types.OBJECT}, map[string]any{}, map[string]any{})
+	assert.Error(t, err)
+}
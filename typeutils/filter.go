@@ -0,0 +1,83 @@
+package typeutils
+
+import (
+	"fmt"
+
+	"github.com/gear5sh/gear5/types"
+)
+
+// Matches evaluates filter on row comparing values as types of columns in schema; like
+// SQL, conditions on NULL values are not matched
+func Matches(filter *types.Filter, schema *types.TypeSchema, row map[string]any) (bool, error) {
+	switch filter.Operator {
+	case "AND", "OR":
+		for _, operand := range filter.Operands {
+			matched, err := Matches(operand, schema, row)
+			if err != nil {
+				return false, err
+			}
+
+			if filter.Operator == "AND" && !matched {
+				return false, nil
+			} else if filter.Operator == "OR" && matched {
+				return true, nil
+			}
+		}
+
+		return filter.Operator == "AND", nil
+	}
+
+	value := row[filter.Column]
+	switch filter.Comparison {
+	case "IS NULL":
+		return value == nil, nil
+	case "IS NOT NULL":
+		return value != nil, nil
+	}
+
+	if value == nil {
+		return false, nil
+	}
+
+	datatype := types.STRING
+	if schema != nil {
+		if property, found := schema.Properties[filter.Column]; found {
+			datatype = property.DataType()
+		}
+	}
+
+	results := []int{}
+	for _, literal := range filter.Values {
+		result, err := Compare(datatype, value, literal)
+		if err != nil {
+			return false, fmt.Errorf("failed to evaluate filter on %s: %s", filter.Column, err)
+		}
+
+		results = append(results, result)
+	}
+
+	switch filter.Comparison {
+	case "=":
+		return results[0] == 0, nil
+	case "!=":
+		return results[0] != 0, nil
+	case "<":
+		return results[0] < 0, nil
+	case "<=":
+		return results[0] <= 0, nil
+	case ">":
+		return results[0] > 0, nil
+	case ">=":
+		return results[0] >= 0, nil
+	case "IN", "NOT IN":
+		for _, result := range results {
+			if result == 0 {
+				return filter.Comparison == "IN", nil
+			}
+		}
+
+		return filter.Comparison == "NOT IN", nil
+	}
+
+	return false, fmt.Errorf("unsupported comparison %s in filter", filter.Comparison)
+}
@@ -0,0 +1,59 @@
+package typeutils
+
+import (
+	"testing"
+
+	"github.com/gear5sh/gear5/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesSignedExponents(t *testing.T) {
+	filter, err := types.ParseFilter("amount > 1e-5 AND amount < 2E+3 AND rate = 1.5e2 AND delta >= -3")
+	require.NoError(t, err)
+
+	schema := &types.TypeSchema{Properties: map[string]*types.Property{
+		"amount": {Type: []types.DataType{types.FLOAT64}},
+		"rate":   {Type: []types.DataType{types.FLOAT64}},
+		"delta":  {Type: []types.DataType{types.INT64}},
+	}}
+
+	matched, err := Matches(filter, schema, map[string]any{"amount": 0.5, "rate": 150, "delta": -3})
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	matched, err = Matches(filter, schema, map[string]any{"amount": 0.000001, "rate": 150, "delta": -3})
+	require.NoError(t, err)
+	assert.False(t, matched)
+}
+
+func TestMatches(t *testing.T) {
+	schema := &types.TypeSchema{Properties: map[string]*types.Property{
+		"tenant_id":  {Type: []types.DataType{types.INT64}},
+		"status":     {Type: []types.DataType{types.STRING, types.NULL}},
+		"amount":     {Type: []types.DataType{types.FLOAT64}},
+		"created_at": {Type: []types.DataType{types.TIMESTAMP}},
+	}}
+
+	filter, err := types.ParseFilter("tenant_id IN (1, 2) AND (status != 'deleted' OR status IS NULL) AND amount >= 10.5 AND created_at > '2024-01-01'")
+	require.NoError(t, err)
+
+	tests := []struct {
+		row     map[string]any
+		matched bool
+	}{
+		{map[string]any{"tenant_id": float64(2), "status": "open", "amount": 11, "created_at": "2024-02-01T00:00:00Z"}, true},
+		{map[string]any{"tenant_id": int32(1), "status": nil, "amount": 10.5, "created_at": "2024-02-01T00:00:00Z"}, true},
+		{map[string]any{"tenant_id": int64(3), "status": "open", "amount": 11, "created_at": "2024-02-01T00:00:00Z"}, false},
+		{map[string]any{"tenant_id": int64(1), "status": "deleted", "amount": 11, "created_at": "2024-02-01T00:00:00Z"}, false},
+		{map[string]any{"tenant_id": int64(1), "status": "open", "amount": 10.4, "created_at": "2024-02-01T00:00:00Z"}, false},
+		{map[string]any{"tenant_id": int64(1), "status": "open", "amount": 11, "created_at": "2023-12-31T00:00:00Z"}, false},
+		{map[string]any{"status": "open", "amount": 11, "created_at": "2024-02-01T00:00:00Z"}, false},
+	}
+
+	for i, test := range tests {
+		matched, err := Matches(filter, schema, test.row)
+		require.NoError(t, err)
+		assert.Equal(t, test.matched, matched, "row %d", i)
+	}
+}
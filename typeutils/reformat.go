@@ -2,10 +2,16 @@ package typeutils
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"math/big"
+	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/goccy/go-json"
+
 	"github.com/gear5sh/gear5/types"
 )
 
@@ -88,23 +94,245 @@ func ReformatValue(dataType types.DataType, v any) (any, error) {
 			return fmt.Sprintf("%t", v), nil
 		case []byte: // byte slice
 			return string(v), nil
+		case [16]byte: // uuid
+			return fmt.Sprintf("%x-%x-%x-%x-%x", v[0:4], v[4:6], v[6:8], v[8:10], v[10:16]), nil
+		case driver.Valuer: // e.g. intervals decoded by pgx
+			value, err := v.Value()
+			if err != nil {
+				return nil, err
+			}
+
+			return ReformatValue(types.STRING, value)
 		default:
 			return fmt.Sprintf("%v", v), nil
 		}
 	case types.FLOAT64:
 		return ReformatFloat64(v)
 	case types.ARRAY:
-		if value, isArray := v.([]any); isArray {
-			return value, nil
+		return ReformatArray(v)
+	case types.DECIMAL:
+		return ReformatDecimal(v)
+	case types.DATE:
+		date, err := ReformatDate(v)
+		if err != nil {
+			return nil, err
 		}
 
-		// make it an array
-		return []any{v}, nil
+		return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC), nil
+	case types.TIME:
+		return ReformatTime(v)
+	case types.JSON:
+		return ReformatJSON(v)
 	default:
 		return v, nil
 	}
 }
 
+// ReformatValueOnProperty reformats v on type of property; array elements are reformatted
+// on type of items
+func ReformatValueOnProperty(property *types.Property, v any) (any, error) {
+	value, err := ReformatValueOnDataTypes(property.Type, v)
+	if err != nil || property.Items == nil || value == nil {
+		return value, err
+	}
+
+	array, isArray := value.([]any)
+	if !isArray {
+		return value, nil
+	}
+
+	items := make([]any, len(array))
+	for i, item := range array {
+		if item == nil {
+			continue
+		}
+
+		items[i], err = ReformatValueOnProperty(property.Items, item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reformat array element %d: %s", i, err)
+		}
+	}
+
+	return items, nil
+}
+
+// ReformatArray returns slices as []any; postgres array literals e.g. {1,2,NULL} are parsed
+// into elements as strings
+func ReformatArray(v any) ([]any, error) {
+	switch value := v.(type) {
+	case []any:
+		return value, nil
+	case []byte:
+		return ReformatArray(string(value))
+	case string:
+		if strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") {
+			array, rest, err := parseArrayLiteral(value)
+			if err != nil {
+				return nil, err
+			}
+
+			if rest != "" {
+				return nil, fmt.Errorf("unexpected %q after array literal", rest)
+			}
+
+			return array, nil
+		}
+	}
+
+	if reflected := reflect.ValueOf(v); reflected.Kind() == reflect.Slice || reflected.Kind() == reflect.Array {
+		array := make([]any, reflected.Len())
+		for i := range array {
+			array[i] = reflected.Index(i).Interface()
+		}
+
+		return array, nil
+	}
+
+	// make it an array
+	return []any{v}, nil
+}
+
+// parseArrayLiteral parses an array literal at start of value; returns rest of value
+func parseArrayLiteral(value string) ([]any, string, error) {
+	array := []any{}
+	rest := value[1:]
+	for {
+		rest = strings.TrimLeft(rest, " ")
+		if rest == "" {
+			return nil, "", fmt.Errorf("unterminated array literal")
+		}
+
+		switch {
+		case rest[0] == '}' && len(array) == 0:
+			return array, rest[1:], nil
+		case rest[0] == '{':
+			nested, remaining, err := parseArrayLiteral(rest)
+			if err != nil {
+				return nil, "", err
+			}
+
+			array = append(array, nested)
+			rest = remaining
+		case rest[0] == '"':
+			element := strings.Builder{}
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				element.WriteByte(rest[i])
+			}
+
+			if i >= len(rest) {
+				return nil, "", fmt.Errorf("unterminated quote in array literal")
+			}
+
+			array = append(array, element.String())
+			rest = rest[i+1:]
+		default:
+			end := strings.IndexAny(rest, ",}")
+			if end < 0 {
+				return nil, "", fmt.Errorf("unterminated array literal")
+			}
+
+			element := strings.TrimSpace(rest[:end])
+			if strings.EqualFold(element, "NULL") {
+				array = append(array, nil)
+			} else {
+				array = append(array, element)
+			}
+			rest = rest[end:]
+		}
+
+		rest = strings.TrimLeft(rest, " ")
+		if rest == "" {
+			return nil, "", fmt.Errorf("unterminated array literal")
+		}
+
+		switch rest[0] {
+		case ',':
+			rest = rest[1:]
+		case '}':
+			return array, rest[1:], nil
+		default:
+			return nil, "", fmt.Errorf("unexpected %q in array literal", rest[0])
+		}
+	}
+}
+
+// ReformatDecimal returns exact text of a decimal number
+func ReformatDecimal(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		switch v = strings.TrimSpace(v); v {
+		case "NaN", "Infinity", "-Infinity":
+			return v, nil
+		}
+
+		if _, ok := new(big.Rat).SetString(v); !ok {
+			return "", fmt.Errorf("failed to change string %v to decimal", v)
+		}
+
+		return v, nil
+	case []byte:
+		return ReformatDecimal(string(v))
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v), nil
+	case driver.Valuer: // e.g. pgtype.Numeric
+		value, err := v.Value()
+		if err != nil {
+			return "", err
+		}
+
+		return ReformatDecimal(value)
+	case StringInterface: // e.g. json.Number
+		return ReformatDecimal(v.String())
+	}
+
+	return "", fmt.Errorf("failed to change %v (type:%T) to decimal", v, v)
+}
+
+// ReformatTime returns time of day as text i.e. 15:04:05.999999 with zone if given
+func ReformatTime(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case time.Time:
+		return v.Format("15:04:05.999999"), nil
+	case driver.Valuer: // e.g. pgtype.Time
+		value, err := v.Value()
+		if err != nil {
+			return "", err
+		}
+
+		return ReformatTime(value)
+	}
+
+	return "", fmt.Errorf("failed to change %v (type:%T) to time", v, v)
+}
+
+// ReformatJSON returns json text as raw json; decoded values are kept
+func ReformatJSON(v any) (any, error) {
+	switch v := v.(type) {
+	case []byte:
+		return ReformatJSON(string(v))
+	case string:
+		if !json.Valid([]byte(v)) {
+			return nil, fmt.Errorf("invalid json: %s", v)
+		}
+
+		return json.RawMessage(v), nil
+	}
+
+	return v, nil
+}
+
 // reformat date
 func ReformatDate(v interface{}) (time.Time, error) {
 	parsed, err := func() (time.Time, error) {
@@ -211,6 +439,12 @@ func ReformatInt64(v any) (int64, error) {
 		return int64(v), nil
 	case uint64:
 		return int64(v), nil
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return int64(0), fmt.Errorf("failed to change string %v to int64: %w", v, err)
+		}
+		return i, nil
 	case *any:
 		return ReformatInt64(*v)
 	}